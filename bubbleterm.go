@@ -26,6 +26,30 @@ type Model struct {
 	frame      emulator.EmittedFrame
 	cachedView string // Cache the rendered view string
 	autoPoll   bool   // Whether to automatically poll for updates
+
+	// Scrollback viewport: lines scrolled back from the live screen, and
+	// the scrollback length and lines dropped from it seen at the last frame
+	// (to keep the view and selection anchored)
+	scrollOffset      int
+	scrollbackLen     int
+	scrollbackDropped int
+
+	// Whether the child shows the alternate screen, on which the scrollback
+	// view and selection are off, and the offset to restore when it leaves
+	altScreen        bool
+	mainScrollOffset int
+
+	inlineCursor bool // Draw the cursor into the content instead of tea.View.Cursor
+	hostFocused  bool // Whether the host terminal has focus (tea.FocusMsg/BlurMsg)
+
+	sanitizePaste func(string) string // Filter applied to pasted text, or nil
+
+	sel selection // Mouse text selection, made while the child does not track the mouse
+
+	linkClicks  bool // Ctrl+click on a hyperlink emits LinkClickedMsg
+	linkPressed bool // The pressed button clicked a link; swallow its release
+
+	events *eventQueue // Exported event messages queued by emulator callbacks
 }
 
 // New creates a new terminal bubble with the specified dimensions
@@ -35,7 +59,7 @@ func New(width, height int) (*Model, error) {
 		return nil, err
 	}
 
-	return &Model{
+	m := &Model{
 		emulator:   emu,
 		width:      width,
 		height:     height,
@@ -43,7 +67,12 @@ func New(width, height int) (*Model, error) {
 		frame:      emulator.EmittedFrame{Rows: make([]string, height)},
 		cachedView: strings.Repeat("\n", height-1), // Initialize with empty lines
 		autoPoll:   true,
-	}, nil
+
+		hostFocused:   true,
+		sanitizePaste: SanitizePaste,
+	}
+	m.wireEvents()
+	return m, nil
 }
 
 func (m *Model) SetAutoPoll(autoPoll bool) {
@@ -68,7 +97,7 @@ func NewWithPipes(width, height int, r io.Reader, w io.WriteCloser) (*Model, err
 		return nil, err
 	}
 
-	return &Model{
+	m := &Model{
 		emulator:   emu,
 		width:      width,
 		height:     height,
@@ -76,7 +105,12 @@ func NewWithPipes(width, height int, r io.Reader, w io.WriteCloser) (*Model, err
 		frame:      emulator.EmittedFrame{Rows: make([]string, height)},
 		cachedView: strings.Repeat("\n", height-1),
 		autoPoll:   true,
-	}, nil
+
+		hostFocused:   true,
+		sanitizePaste: SanitizePaste,
+	}
+	m.wireEvents()
+	return m, nil
 }
 
 // NewWithCommand creates a new terminal bubble and starts the specified command
@@ -101,7 +135,7 @@ func (m *Model) Init() tea.Cmd {
 	// Otherwise grab the initial frame once and let the external ticker
 	// drive subsequent updates.
 	if m.autoPoll {
-		return pollTerminal(m.emulator)
+		return m.poll()
 	}
 	return pollTerminalOnce(m.emulator)
 }
@@ -114,30 +148,71 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		// Convert bubbletea key events to terminal input
-		input := keyToTerminalInput(msg)
+		if m.handleScrollKey(msg) {
+			return m, nil
+		}
+
+		// Convert bubbletea key events to terminal input, honoring the
+		// cursor key and keypad modes the child has set
+		input := encodeKey(msg, terminalKeyModes(m.emulator))
 		if input != "" {
+			m.ScrollToBottom()
 			return m, sendInput(m.emulator, input)
 		}
 
+	case tea.PasteMsg:
+		if !m.focused {
+			return m, nil
+		}
+
+		text := msg.Content
+		if m.sanitizePaste != nil {
+			text = m.sanitizePaste(text)
+		}
+		if text != "" {
+			m.ScrollToBottom()
+			return m, sendPaste(m.emulator, text)
+		}
+
+	case tea.FocusMsg:
+		return m, m.setFocus(m.focused, true)
+
+	case tea.BlurMsg:
+		return m, m.setFocus(m.focused, false)
+
 	case tea.MouseClickMsg:
 		if !m.focused {
 			return m, nil
 		}
+		if cmd, ok := m.clickLink(msg, msg.Mouse().X, msg.Mouse().Y); ok {
+			return m, cmd
+		}
+		if m.selectMouse(msg, msg.Mouse().X, msg.Mouse().Y) {
+			return m, nil
+		}
 		// Send mouse click to terminal
-		return m, sendMouseEvent(m.emulator, msg.Mouse().X, msg.Mouse().Y, int(msg.Mouse().Button), true)
+		return m, sendMouseEvent(m.emulator, msg.Mouse().X, msg.Mouse().Y, mouseButton(msg.Mouse().Button), true)
 
 	case tea.MouseReleaseMsg:
 		if !m.focused {
 			return m, nil
 		}
+		if cmd, ok := m.clickLink(msg, msg.Mouse().X, msg.Mouse().Y); ok {
+			return m, cmd
+		}
+		if m.selectMouse(msg, msg.Mouse().X, msg.Mouse().Y) {
+			return m, nil
+		}
 		// Send mouse release to terminal
-		return m, sendMouseEvent(m.emulator, msg.Mouse().X, msg.Mouse().Y, int(msg.Mouse().Button), false)
+		return m, sendMouseEvent(m.emulator, msg.Mouse().X, msg.Mouse().Y, mouseButton(msg.Mouse().Button), false)
 
 	case tea.MouseMotionMsg:
 		if !m.focused {
 			return m, nil
 		}
+		if m.selectMouse(msg, msg.Mouse().X, msg.Mouse().Y) {
+			return m, nil
+		}
 		// Send mouse motion to terminal (button -1 indicates motion without button)
 		return m, sendMouseEvent(m.emulator, msg.Mouse().X, msg.Mouse().Y, -1, false)
 
@@ -145,6 +220,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if !m.focused {
 			return m, nil
 		}
+		if !m.emulator.IsMouseTracking() {
+			m.scrollWheel(msg.Button)
+			return m, nil
+		}
 		return m, sendMouseWheel(m.emulator, msg.Mouse().X, msg.Mouse().Y, int(msg.Mouse().Button))
 
 	case translatedMouseMsg:
@@ -154,15 +233,27 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.EmulatorID != m.emulator.ID() {
 			return m, nil // Ignore messages from other emulators
 		}
+		if mouseMsg, ok := msg.OriginalMsg.(tea.MouseMsg); ok {
+			if cmd, ok := m.clickLink(mouseMsg, msg.X, msg.Y); ok {
+				return m, cmd
+			}
+			if m.selectMouse(mouseMsg, msg.X, msg.Y) {
+				return m, nil
+			}
+		}
 		// Handle translated mouse events with proper coordinates
 		switch originalMsg := msg.OriginalMsg.(type) {
 		case tea.MouseClickMsg:
-			return m, sendMouseEvent(m.emulator, msg.X, msg.Y, int(originalMsg.Mouse().Button), true)
+			return m, sendMouseEvent(m.emulator, msg.X, msg.Y, mouseButton(originalMsg.Mouse().Button), true)
 		case tea.MouseReleaseMsg:
-			return m, sendMouseEvent(m.emulator, msg.X, msg.Y, int(originalMsg.Mouse().Button), false)
+			return m, sendMouseEvent(m.emulator, msg.X, msg.Y, mouseButton(originalMsg.Mouse().Button), false)
 		case tea.MouseMotionMsg:
 			return m, sendMouseEvent(m.emulator, msg.X, msg.Y, -1, false)
 		case tea.MouseWheelMsg:
+			if !m.emulator.IsMouseTracking() {
+				m.scrollWheel(originalMsg.Button)
+				return m, nil
+			}
 			return m, sendMouseWheel(m.emulator, msg.X, msg.Y, int(originalMsg.Mouse().Button))
 		}
 
@@ -178,20 +269,33 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.EmulatorID != m.emulator.ID() {
 			return m, nil // Ignore messages from other emulators
 		}
-		if len(msg.Frame.Damage) == 0 {
-			// The auto-poll loop only emits damaged frames, so this path
+		if !frameChanged(msg.Frame) {
+			// The auto-poll loop only emits changed frames, so this path
 			// is only reachable from pollTerminalOnce or manual GetScreen
 			// calls. Rescheduling a blocking poll on every undamaged
 			// message would accumulate goroutines, so do nothing here.
 			return m, nil
 		}
 		m.frame = msg.Frame
-		m.cachedView = strings.Join(m.frame.Rows, "\n")
+		m.followScreenSwitch()
+		m.followScrollback()
+		m.followDamage()
+		m.renderView()
 		if m.autoPoll {
-			return m, pollTerminal(m.emulator)
+			return m, m.poll()
 		}
 		return m, nil
 
+	case terminalEventMsg:
+		if msg.EmulatorID != m.emulator.ID() {
+			return m, nil // Ignore messages from other emulators
+		}
+		emit := func() tea.Msg { return msg.Msg }
+		if m.autoPoll {
+			return m, tea.Batch(m.poll(), emit)
+		}
+		return m, emit
+
 	case terminalErrorMsg:
 		if msg.EmulatorID != m.emulator.ID() {
 			return m, nil // Ignore messages from other emulators
@@ -213,9 +317,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// UpdateTerminal manually polls the terminal for updates (called by external ticker)
+// UpdateTerminal manually polls the terminal for updates (called by external ticker).
+// It also delivers queued event messages such as TitleChangedMsg.
 func (m *Model) UpdateTerminal() tea.Cmd {
-	return pollTerminalOnce(m.emulator)
+	return tea.Batch(append([]tea.Cmd{pollTerminalOnce(m.emulator)}, m.drainEvents()...)...)
 }
 
 // View renders the terminal output.
@@ -225,17 +330,21 @@ func (m *Model) View() tea.View {
 	}
 
 	// Return cached view for maximum performance
-	return tea.NewView(m.cachedView)
+	v := tea.NewView(m.cachedView)
+	v.Cursor = m.viewCursor()
+	return v
 }
 
-// Focus sets the bubble as focused (receives keyboard input)
-func (m *Model) Focus() {
-	m.focused = true
+// Focus sets the bubble as focused (receives keyboard input). The returned
+// command reports the focus change to the child if it enabled focus events.
+func (m *Model) Focus() tea.Cmd {
+	return m.setFocus(true, m.hostFocused)
 }
 
-// Blur removes focus from the bubble
-func (m *Model) Blur() {
-	m.focused = false
+// Blur removes focus from the bubble. The returned command reports the focus
+// change to the child if it enabled focus events.
+func (m *Model) Blur() tea.Cmd {
+	return m.setFocus(false, m.hostFocused)
 }
 
 // Focused returns whether the bubble is currently focused
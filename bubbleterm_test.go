@@ -235,6 +235,115 @@ func TestKeyToTerminalInput(t *testing.T) {
 	}
 }
 
+func TestEncodeKeyModes(t *testing.T) {
+	appCursor := keyModes{appCursor: true}
+	appKeypad := keyModes{appKeypad: true}
+	tests := []struct {
+		name     string
+		msg      tea.KeyPressMsg
+		modes    keyModes
+		expected string
+	}{
+		// Application cursor keys (DECCKM) switch unmodified keys to SS3
+		{"up normal", tea.KeyPressMsg{Code: tea.KeyUp}, keyModes{}, "\x1b[A"},
+		{"up app", tea.KeyPressMsg{Code: tea.KeyUp}, appCursor, "\x1bOA"},
+		{"down app", tea.KeyPressMsg{Code: tea.KeyDown}, appCursor, "\x1bOB"},
+		{"right app", tea.KeyPressMsg{Code: tea.KeyRight}, appCursor, "\x1bOC"},
+		{"left app", tea.KeyPressMsg{Code: tea.KeyLeft}, appCursor, "\x1bOD"},
+		{"home app", tea.KeyPressMsg{Code: tea.KeyHome}, appCursor, "\x1bOH"},
+		{"end app", tea.KeyPressMsg{Code: tea.KeyEnd}, appCursor, "\x1bOF"},
+		{"begin app", tea.KeyPressMsg{Code: tea.KeyBegin}, appCursor, "\x1bOE"},
+		{"kp up app", tea.KeyPressMsg{Code: tea.KeyKpUp}, appCursor, "\x1bOA"},
+		{"ctrl+up app", tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModCtrl}, appCursor, "\x1b[1;5A"},
+		{"pgup app", tea.KeyPressMsg{Code: tea.KeyPgUp}, appCursor, "\x1b[5~"},
+		{"kp 1 app cursor", tea.KeyPressMsg{Code: tea.KeyKp1}, appCursor, "1"},
+
+		// Numeric keypad sends the characters on the key caps
+		{"kp 0 numeric", tea.KeyPressMsg{Code: tea.KeyKp0}, keyModes{}, "0"},
+		{"kp enter numeric", tea.KeyPressMsg{Code: tea.KeyKpEnter}, keyModes{}, "\r"},
+		{"kp plus numeric", tea.KeyPressMsg{Code: tea.KeyKpPlus, Text: "+"}, keyModes{}, "+"},
+		{"alt+kp 5 numeric", tea.KeyPressMsg{Code: tea.KeyKp5, Mod: tea.ModAlt}, keyModes{}, "\x1b5"},
+
+		// Application keypad (DECKPAM) sends SS3 sequences
+		{"kp 0 app", tea.KeyPressMsg{Code: tea.KeyKp0}, appKeypad, "\x1bOp"},
+		{"kp 9 app", tea.KeyPressMsg{Code: tea.KeyKp9}, appKeypad, "\x1bOy"},
+		{"kp enter app", tea.KeyPressMsg{Code: tea.KeyKpEnter}, appKeypad, "\x1bOM"},
+		{"kp equal app", tea.KeyPressMsg{Code: tea.KeyKpEqual}, appKeypad, "\x1bOX"},
+		{"kp multiply app", tea.KeyPressMsg{Code: tea.KeyKpMultiply}, appKeypad, "\x1bOj"},
+		{"kp plus app", tea.KeyPressMsg{Code: tea.KeyKpPlus, Text: "+"}, appKeypad, "\x1bOk"},
+		{"kp comma app", tea.KeyPressMsg{Code: tea.KeyKpComma}, appKeypad, "\x1bOl"},
+		{"kp minus app", tea.KeyPressMsg{Code: tea.KeyKpMinus}, appKeypad, "\x1bOm"},
+		{"kp decimal app", tea.KeyPressMsg{Code: tea.KeyKpDecimal}, appKeypad, "\x1bOn"},
+		{"kp divide app", tea.KeyPressMsg{Code: tea.KeyKpDivide}, appKeypad, "\x1bOo"},
+		{"shift+kp 1 app", tea.KeyPressMsg{Code: tea.KeyKp1, Mod: tea.ModShift}, appKeypad, "\x1b[1;2q"},
+		{"up app keypad", tea.KeyPressMsg{Code: tea.KeyUp}, appKeypad, "\x1b[A"},
+		{"digit app keypad", tea.KeyPressMsg{Code: '1', Text: "1"}, appKeypad, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := encodeKey(tt.msg, tt.modes)
+			if got != tt.expected {
+				t.Errorf("encodeKey(%q, %+v) = %q, want %q", tt.msg.String(), tt.modes, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestModelUpdateKeyMsgHonorsTerminalModes(t *testing.T) {
+	pr, pw := io.Pipe()
+	ir, iw := io.Pipe()
+
+	model, err := NewWithPipes(80, 24, pr, iw)
+	if err != nil {
+		t.Fatalf("NewWithPipes failed: %v", err)
+	}
+	defer model.Close()
+
+	// Enable DECCKM and DECKPAM from the child's side
+	if _, err := pw.Write([]byte("\x1b[?1h\x1b=")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	emu := model.GetEmulator()
+	for !emu.IsApplicationCursorKeys() || !emu.IsApplicationKeypad() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the modes to be set")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	for _, tt := range []struct {
+		msg      tea.KeyPressMsg
+		expected string
+	}{
+		{tea.KeyPressMsg{Code: tea.KeyUp}, "\x1bOA"},
+		{tea.KeyPressMsg{Code: tea.KeyKpEnter}, "\x1bOM"},
+	} {
+		_, cmd := model.Update(tt.msg)
+		if cmd == nil {
+			t.Fatalf("expected command for %q", tt.msg.String())
+		}
+
+		done := make(chan string, 1)
+		go func() {
+			buf := make([]byte, 8)
+			n, _ := ir.Read(buf)
+			done <- string(buf[:n])
+		}()
+		cmd()
+
+		select {
+		case got := <-done:
+			if got != tt.expected {
+				t.Fatalf("%q sent %q, want %q", tt.msg.String(), got, tt.expected)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for translated input")
+		}
+	}
+}
+
 func TestNew(t *testing.T) {
 	model, err := New(80, 24)
 	if err != nil {
@@ -614,15 +723,52 @@ func TestModelUpdateMouseMsgReturnsSendCommand(t *testing.T) {
 	}
 }
 
+func TestModelUpdateMouseFollowsTrackingMode(t *testing.T) {
+	model, pw, input := newInputModel(t)
+	emu := model.GetEmulator()
+
+	// Without tracking, clicks select text instead of being reported
+	_, cmd := model.Update(tea.MouseClickMsg{X: 1, Y: 2, Button: tea.MouseLeft})
+	if cmd != nil {
+		t.Fatal("expected click to start a selection without mouse tracking")
+	}
+	model.Update(tea.MouseReleaseMsg{X: 1, Y: 2, Button: tea.MouseLeft})
+	expectNoInput(t, input)
+
+	writeMode(t, pw, "\x1b[?1000h\x1b[?1006h", func() bool {
+		_, enc := emu.MouseMode()
+		return enc == emulator.MouseEncodingSGR
+	})
+	for _, tt := range []struct {
+		msg      tea.Msg
+		expected string
+	}{
+		{tea.MouseClickMsg{X: 1, Y: 2, Button: tea.MouseLeft}, "\x1b[<0;2;3M"},
+		{tea.MouseReleaseMsg{X: 1, Y: 2, Button: tea.MouseLeft}, "\x1b[<0;2;3m"},
+		{tea.MouseClickMsg{X: 1, Y: 2, Button: tea.MouseRight}, "\x1b[<2;2;3M"},
+	} {
+		_, cmd := model.Update(tt.msg)
+		cmd()
+		expectInput(t, input, tt.expected)
+	}
+
+	// Normal tracking does not report motion
+	_, cmd = model.Update(tea.MouseMotionMsg{X: 4, Y: 4})
+	cmd()
+	expectNoInput(t, input)
+}
+
 func TestModelUpdateHandlesMouseWheelMsg(t *testing.T) {
-	pr, _ := io.Pipe()
-	_, iw := io.Pipe()
+	pr, pw := io.Pipe()
+	ir, iw := io.Pipe()
+	go io.Copy(io.Discard, ir) // drain the encoded wheel reports
 
 	model, err := NewWithPipes(80, 24, pr, iw)
 	if err != nil {
 		t.Fatalf("NewWithPipes failed: %v", err)
 	}
 	defer model.Close()
+	enableMouseTracking(t, model, pw)
 
 	// MouseWheelMsg should produce a command (not be silently dropped)
 	_, cmd := model.Update(tea.MouseWheelMsg{X: 5, Y: 5, Button: tea.MouseWheelUp})
@@ -691,14 +837,16 @@ func TestModelUpdateTranslatedMouseIgnoresWrongEmulatorAndUnknownMessage(t *test
 }
 
 func TestModelUpdateHandlesTranslatedMouseWheelMsg(t *testing.T) {
-	pr, _ := io.Pipe()
-	_, iw := io.Pipe()
+	pr, pw := io.Pipe()
+	ir, iw := io.Pipe()
+	go io.Copy(io.Discard, ir) // drain the encoded wheel reports
 
 	model, err := NewWithPipes(80, 24, pr, iw)
 	if err != nil {
 		t.Fatalf("NewWithPipes failed: %v", err)
 	}
 	defer model.Close()
+	enableMouseTracking(t, model, pw)
 
 	msg := translatedMouseMsg{
 		EmulatorID:  model.emulator.ID(),
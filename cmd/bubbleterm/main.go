@@ -5,6 +5,7 @@ import (
 	"os/exec"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/taigrr/bubbleterm"
 )
 
@@ -33,8 +34,7 @@ func main() {
 }
 
 func (m *model) Init() tea.Cmd {
-	m.terminal.Focus()
-	return m.terminal.Init()
+	return tea.Batch(m.terminal.Focus(), m.terminal.Init())
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -45,6 +45,13 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.terminal.Close()
 			return m, tea.Quit
 		}
+	case bubbleterm.ClipboardMsg:
+		// Copy what the embedded program copied with OSC 52 to the real
+		// terminal's clipboard
+		if msg.Selection == ansi.PrimaryClipboard {
+			return m, tea.SetPrimaryClipboard(msg.Text)
+		}
+		return m, tea.SetClipboard(msg.Text)
 	}
 
 	// Forward all messages to the terminal bubble
@@ -62,5 +69,9 @@ func (m *model) View() tea.View {
 
 	v := m.terminal.View()
 	v.AltScreen = true
+	v.ReportFocus = true
+	// Ask for key releases so programs using the kitty keyboard protocol
+	// can receive them
+	v.KeyboardEnhancements.ReportEventTypes = true
 	return v
 }
@@ -6,10 +6,12 @@ import (
 	"os"
 	"os/exec"
 	"slices"
+	"strings"
 	"time"
 
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/google/uuid"
 	"github.com/taigrr/bubbleterm"
 )
@@ -91,7 +93,7 @@ func (m *MultiWindowOS) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.FocusedWindow >= 0 && m.FocusedWindow < len(m.Windows) {
 					m.InsertMode = true
 					// Focus the terminal
-					m.Windows[m.FocusedWindow].Terminal.Focus()
+					return m, m.Windows[m.FocusedWindow].Terminal.Focus()
 				}
 				return m, nil
 			case "=", "+":
@@ -119,7 +121,7 @@ func (m *MultiWindowOS) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.InsertMode = false
 				// Blur the focused terminal
 				if m.FocusedWindow >= 0 && m.FocusedWindow < len(m.Windows) {
-					m.Windows[m.FocusedWindow].Terminal.Blur()
+					return m, m.Windows[m.FocusedWindow].Terminal.Blur()
 				}
 				return m, nil
 			}
@@ -127,7 +129,9 @@ func (m *MultiWindowOS) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Forward all other keys to the focused terminal
 			if m.FocusedWindow >= 0 && m.FocusedWindow < len(m.Windows) {
 				// Make sure terminal is focused before sending input
-				m.Windows[m.FocusedWindow].Terminal.Focus()
+				if cmd := m.Windows[m.FocusedWindow].Terminal.Focus(); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
 				terminalModel, cmd := m.Windows[m.FocusedWindow].Terminal.Update(msg)
 				m.Windows[m.FocusedWindow].Terminal = terminalModel.(*bubbleterm.Model)
 				if cmd != nil {
@@ -202,6 +206,16 @@ func (m *MultiWindowOS) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case tea.PasteMsg:
+		// Forward pastes to the focused terminal in insert mode
+		if m.InsertMode && m.FocusedWindow >= 0 && m.FocusedWindow < len(m.Windows) {
+			terminalModel, cmd := m.Windows[m.FocusedWindow].Terminal.Update(msg)
+			m.Windows[m.FocusedWindow].Terminal = terminalModel.(*bubbleterm.Model)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+
 	case tea.WindowSizeMsg:
 		// Update our screen dimensions
 		m.width = msg.Width
@@ -215,6 +229,24 @@ func (m *MultiWindowOS) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case tea.FocusMsg, tea.BlurMsg:
+		// Let the terminals report host focus changes to their programs
+		for i := range m.Windows {
+			terminalModel, cmd := m.Windows[i].Terminal.Update(msg)
+			m.Windows[i].Terminal = terminalModel.(*bubbleterm.Model)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+
+	case bubbleterm.TitleChangedMsg:
+		// Label the window with the title set by the program running in it
+		for i := range m.Windows {
+			if m.Windows[i].Terminal.GetEmulator().ID() == msg.ID && msg.Title != "" {
+				m.Windows[i].Title = msg.Title
+			}
+		}
+
 	case centralTickMsg:
 		// Centralized terminal updates - poll all terminals
 		var deadWindows []int
@@ -311,6 +343,14 @@ func (m *MultiWindowOS) createNewTerminalWindow(x, y int) tea.Cmd {
 		"PATH=/usr/local/bin:/usr/bin:/bin",
 		"HOME=" + os.Getenv("HOME"),
 	}
+	// Open the new terminal in the focused terminal's directory, if it
+	// still exists
+	if m.FocusedWindow >= 0 && m.FocusedWindow < len(m.Windows) {
+		dir := m.Windows[m.FocusedWindow].Terminal.GetEmulator().WorkingDir()
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			cmd.Dir = dir
+		}
+	}
 
 	newID := createID()
 	// Account for border (1px) + padding (2px) = 3px total on each side
@@ -322,6 +362,11 @@ func (m *MultiWindowOS) createNewTerminalWindow(x, y int) tea.Cmd {
 	}
 	// disable auto-polling to avoid conflicts with our centralized tick
 	terminal.SetAutoPoll(false)
+	// Windows are composited from View().Content, which drops tea.View's
+	// cursor, so draw it inline. Only the window in insert mode is focused.
+	// The child has not enabled focus reports yet, so there is nothing to send.
+	terminal.SetInlineCursor(true)
+	terminal.Blur()
 
 	window := TerminalWindow{
 		Title:    fmt.Sprintf("Terminal %d", len(m.Windows)+1),
@@ -465,7 +510,7 @@ func (m *MultiWindowOS) GetLayers() []*lipgloss.Layer {
 			Border(lipgloss.RoundedBorder()).
 			Background(lipgloss.Color("#000000"))
 
-		content := box.Render(terminalContent.Content)
+		content := withBorderTitle(box.Render(terminalContent.Content), window.Title)
 
 		layer := lipgloss.NewLayer(content).
 			X(window.X).
@@ -502,5 +547,18 @@ func (m *MultiWindowOS) View() tea.View {
 	v.SetContent(canvas.Render() + "\n" + statusStyle.Render(status))
 	v.AltScreen = true
 	v.MouseMode = tea.MouseModeAllMotion
+	v.ReportFocus = true
 	return v
 }
+
+// withBorderTitle writes title into the top border of a rendered box, keeping
+// the corners intact.
+func withBorderTitle(box, title string) string {
+	top, rest, _ := strings.Cut(box, "\n")
+	label := ansi.Truncate(" "+title+" ", ansi.StringWidth(top)-4, "…")
+	if label == "" {
+		return box
+	}
+	top = ansi.Truncate(top, 2, "") + label + ansi.TruncateLeft(top, 2+ansi.StringWidth(label), "")
+	return top + "\n" + rest
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -29,3 +30,17 @@ func TestCreateNewTerminalWindow_CommandFailure(t *testing.T) {
 		t.Fatal("expected nil command when terminal creation fails")
 	}
 }
+
+func TestWithBorderTitle(t *testing.T) {
+	box := "╭──────────╮\n│          │\n╰──────────╯"
+	got := withBorderTitle(box, "vim")
+	want := "╭─ vim ────╮\n│          │\n╰──────────╯"
+	if got != want {
+		t.Fatalf("withBorderTitle() = %q, want %q", got, want)
+	}
+
+	got = withBorderTitle(box, "a very long title")
+	if top, _, _ := strings.Cut(got, "\n"); top != "╭─ a very…─╮" {
+		t.Fatalf("expected truncated title, got %q", top)
+	}
+}
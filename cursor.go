@@ -0,0 +1,81 @@
+package bubbleterm
+
+import (
+	"slices"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/taigrr/bubbleterm/emulator"
+)
+
+// SetInlineCursor controls how the child's cursor is shown while the model is
+// focused. By default View sets tea.View.Cursor so the real terminal cursor is
+// placed over the child's cursor. With inline set, View instead draws a styled
+// block cell into the content, which survives parents that composite several
+// views' Content into one screen (see cmd/multiwindow).
+func (m *Model) SetInlineCursor(inline bool) {
+	m.inlineCursor = inline
+	m.renderView()
+}
+
+// showCursor reports whether the child's cursor should be drawn.
+func (m *Model) showCursor() bool {
+	cur := m.frame.Cursor
+	return m.focused && m.err == nil && m.scrollOffset == 0 && cur.Visible &&
+		cur.Pos.Y >= 0 && cur.Pos.Y < len(m.frame.Rows) &&
+		cur.Pos.X >= 0 && cur.Pos.X < m.width
+}
+
+// viewCursor returns the cursor View reports to bubbletea, or nil when no
+// cursor should be shown.
+func (m *Model) viewCursor() *tea.Cursor {
+	if m.inlineCursor || !m.showCursor() {
+		return nil
+	}
+	cur := m.frame.Cursor
+	c := tea.NewCursor(cur.Pos.X, cur.Pos.Y)
+	c.Blink = cur.Blink
+	c.Color = cur.Color
+	switch cur.Shape {
+	case emulator.CursorUnderline:
+		c.Shape = tea.CursorUnderline
+	case emulator.CursorBar:
+		c.Shape = tea.CursorBar
+	default:
+		c.Shape = tea.CursorBlock
+	}
+	return c
+}
+
+// withInlineCursor returns a copy of rows with the cell under the cursor
+// drawn as a block: reverse video, or the cursor color as background when the
+// child set one.
+func withInlineCursor(rows []string, cur emulator.CursorState) []string {
+	row := rows[cur.Pos.Y]
+	x := cur.Pos.X
+
+	end := x + 1
+	cell := ansi.Strip(ansi.Cut(row, x, end))
+	if cell == "" {
+		// A wide character starts here and does not fit in one column.
+		end = x + 2
+		cell = ansi.Strip(ansi.Cut(row, x, end))
+	}
+	if cell == "" {
+		end = x + 1
+		cell = " "
+	}
+
+	style := ansi.Style{}.Reverse(true)
+	if cur.Color != nil {
+		style = ansi.Style{}.BackgroundColor(cur.Color)
+	}
+	if cur.Blink {
+		style = style.Blink(true)
+	}
+
+	rows = slices.Clone(rows)
+	rows[cur.Pos.Y] = ansi.Truncate(row, x, "") + ansi.ResetStyle +
+		style.String() + cell + ansi.ResetStyle + ansi.TruncateLeft(row, end, "")
+	return rows
+}
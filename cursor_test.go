@@ -0,0 +1,140 @@
+package bubbleterm
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/taigrr/bubbleterm/emulator"
+)
+
+// newCursorModel returns a 20x3 pipe-backed model with its initial frame
+// applied.
+func newCursorModel(t *testing.T) (*Model, *io.PipeWriter) {
+	t.Helper()
+	pr, pw := io.Pipe()
+	ir, iw := io.Pipe()
+	go io.Copy(io.Discard, ir)
+
+	model, err := NewWithPipes(20, 3, pr, iw)
+	if err != nil {
+		t.Fatalf("NewWithPipes failed: %v", err)
+	}
+	t.Cleanup(func() { model.Close() })
+	applyFrame(model)
+	return model, pw
+}
+
+// writeChild writes s as the child's output, waits until cond holds on the
+// emulator, and applies the resulting frame.
+func writeChild(t *testing.T, model *Model, pw *io.PipeWriter, s string, cond func(emulator.CursorState) bool) {
+	t.Helper()
+	if _, err := pw.Write([]byte(s)); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond(model.GetEmulator().CursorState()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q to be processed", s)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	applyFrame(model)
+}
+
+func TestViewSetsCursorWhenFocused(t *testing.T) {
+	model, pw := newCursorModel(t)
+
+	writeChild(t, model, pw, "ab\x1b[6 q", func(c emulator.CursorState) bool {
+		return c.Pos.X == 2 && c.Shape == emulator.CursorBar
+	})
+
+	cur := model.View().Cursor
+	if cur == nil {
+		t.Fatal("expected a cursor in the view")
+	}
+	if cur.X != 2 || cur.Y != 0 {
+		t.Fatalf("cursor at (%d,%d), want (2,0)", cur.X, cur.Y)
+	}
+	if cur.Shape != tea.CursorBar || cur.Blink {
+		t.Fatalf("cursor shape %v blink %v, want steady bar", cur.Shape, cur.Blink)
+	}
+
+	model.Blur()
+	if model.View().Cursor != nil {
+		t.Fatal("expected no cursor when blurred")
+	}
+}
+
+func TestViewHidesCursorOnDECTCEM(t *testing.T) {
+	model, pw := newCursorModel(t)
+
+	writeChild(t, model, pw, "\x1b[?25l", func(c emulator.CursorState) bool {
+		return !c.Visible
+	})
+	if model.View().Cursor != nil {
+		t.Fatal("expected no cursor while the child hides it")
+	}
+}
+
+func TestViewInlineCursor(t *testing.T) {
+	model, pw := newCursorModel(t)
+	model.SetInlineCursor(true)
+
+	writeChild(t, model, pw, "abc\x1b[1;2H", func(c emulator.CursorState) bool {
+		return c.Pos.X == 1
+	})
+
+	v := model.View()
+	if v.Cursor != nil {
+		t.Fatal("expected no tea cursor with an inline cursor")
+	}
+	row := strings.Split(v.Content, "\n")[0]
+	if !strings.Contains(row, "\x1b[7;5mb\x1b[m") {
+		t.Fatalf("expected reverse-video cursor cell on b, got %q", row)
+	}
+
+	model.Blur()
+	row = strings.Split(model.View().Content, "\n")[0]
+	if strings.Contains(row, "\x1b[7;5m") {
+		t.Fatalf("expected no inline cursor when blurred, got %q", row)
+	}
+}
+
+func TestWithInlineCursorWideChar(t *testing.T) {
+	rows := []string{"\x1b[1ma世b\x1b[0m"}
+	got := withInlineCursor(rows, emulator.CursorState{Pos: emulator.Pos{X: 1}, Visible: true})
+	if !strings.Contains(got[0], "\x1b[7m世\x1b[m") {
+		t.Fatalf("expected wide cursor cell, got %q", got[0])
+	}
+	if !strings.HasSuffix(got[0], "b\x1b[0m") {
+		t.Fatalf("expected the rest of the row after the cursor, got %q", got[0])
+	}
+	if rows[0] != "\x1b[1ma世b\x1b[0m" {
+		t.Fatal("withInlineCursor modified its input")
+	}
+}
+
+func TestPollTerminalOnceReportsCursorOnlyChange(t *testing.T) {
+	model, pw := newCursorModel(t)
+
+	if _, err := pw.Write([]byte("\x1b[?25l")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		msg := pollTerminalOnce(model.GetEmulator())()
+		if out, ok := msg.(terminalOutputMsg); ok {
+			if out.Frame.Cursor.Visible {
+				t.Fatal("expected the frame to carry the hidden cursor")
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for a cursor-only frame")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
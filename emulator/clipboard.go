@@ -0,0 +1,187 @@
+package emulator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// clipboardSelections are the OSC 52 selection names: clipboard, primary,
+// secondary, select and the cut buffers.
+const clipboardSelections = "cpqs01234567"
+
+// Clipboard stores the text the child copies with OSC 52 and supplies the
+// text it may read back. Selection is the OSC 52 selection name, such as
+// ansi.SystemClipboard or ansi.PrimaryClipboard. Methods are called from the
+// read loop goroutine, or from AnswerClipboardRead, without the emulator's
+// lock held.
+type Clipboard interface {
+	// WriteClipboard stores text in selection. An empty text clears it.
+	WriteClipboard(selection byte, text string) error
+	// ReadClipboard returns the text stored in selection.
+	ReadClipboard(selection byte) (string, error)
+}
+
+// ClipboardReadPolicy controls how OSC 52 clipboard queries from the child
+// are answered.
+type ClipboardReadPolicy int
+
+const (
+	// ClipboardReadDeny answers every query with an empty clipboard. It is
+	// the default, so programs cannot read the clipboard silently.
+	ClipboardReadDeny ClipboardReadPolicy = iota
+	// ClipboardReadAllow answers queries with the Clipboard's contents.
+	ClipboardReadAllow
+	// ClipboardReadAsk reports queries to the SetOnClipboardRead callback
+	// and answers each once AnswerClipboardRead is called. Queries are denied
+	// when no callback is set.
+	ClipboardReadAsk
+)
+
+// registerClipboardHandlers hooks the vt parser to handle OSC 52 clipboard
+// requests, which vt ignores.
+func (e *Emulator) registerClipboardHandlers() {
+	// OSC 52 ; Pc ; Pd sets the selections named in Pc to the base64 data
+	// Pd, or queries the first one when Pd is "?". Data that is not valid
+	// base64 clears the selections.
+	e.vt.RegisterOscHandler(52, func(data []byte) bool {
+		_, rest, _ := bytes.Cut(data, []byte{';'})
+		names, payload, ok := bytes.Cut(rest, []byte{';'})
+		if !ok {
+			return true
+		}
+		var selections []byte
+		for _, c := range names {
+			if strings.IndexByte(clipboardSelections, c) >= 0 {
+				selections = append(selections, c)
+			}
+		}
+		if len(names) == 0 {
+			selections = []byte{ansi.SystemClipboard}
+		}
+		if len(selections) == 0 {
+			return true
+		}
+
+		if string(payload) == "?" {
+			e.queryClipboard(selections[0])
+			return true
+		}
+		text, err := base64.StdEncoding.DecodeString(string(payload))
+		if err != nil {
+			text = nil
+		}
+		for _, selection := range selections {
+			e.writeClipboard(selection, string(text))
+		}
+		return true
+	})
+}
+
+// writeClipboard queues storing text in selection and the clipboard
+// callback. Must be called with mu held.
+func (e *Emulator) writeClipboard(selection byte, text string) {
+	clipboard, onClipboard, id := e.clipboard, e.onClipboard, e.id
+	if clipboard == nil && onClipboard == nil {
+		return
+	}
+	e.queueEvent(func() {
+		if clipboard != nil {
+			_ = clipboard.WriteClipboard(selection, text)
+		}
+		if onClipboard != nil {
+			onClipboard(id, selection, text)
+		}
+	})
+}
+
+// queryClipboard answers an OSC 52 query according to the read policy. Must
+// be called with mu held.
+func (e *Emulator) queryClipboard(selection byte) {
+	switch {
+	case e.clipboardRead == ClipboardReadAllow:
+		clipboard := e.clipboard
+		e.queueEvent(func() { e.replyClipboard(clipboard, selection) })
+	case e.clipboardRead == ClipboardReadAsk && e.onClipboardRead != nil:
+		e.clipboardAsks = append(e.clipboardAsks, selection)
+		onClipboardRead, id := e.onClipboardRead, e.id
+		e.queueEvent(func() { onClipboardRead(id, selection) })
+	default:
+		e.replyClipboard(nil, selection)
+	}
+}
+
+// replyClipboard sends the contents of selection in clipboard to the child,
+// or an empty selection when clipboard is nil or cannot be read.
+func (e *Emulator) replyClipboard(clipboard Clipboard, selection byte) {
+	var text string
+	if clipboard != nil {
+		if t, err := clipboard.ReadClipboard(selection); err == nil {
+			text = t
+		}
+	}
+	_, _ = io.WriteString(e.vt.InputPipe(), ansi.SetClipboard(selection, text))
+}
+
+// AnswerClipboardRead answers the oldest pending OSC 52 query for selection
+// made under ClipboardReadAsk, replying with the Clipboard's contents if allow
+// is set and with an empty clipboard otherwise. It returns
+// ErrNoClipboardRequest when no such query is pending.
+func (e *Emulator) AnswerClipboardRead(selection byte, allow bool) error {
+	e.mu.Lock()
+	i := slices.Index(e.clipboardAsks, selection)
+	if i < 0 {
+		e.mu.Unlock()
+		return ErrNoClipboardRequest
+	}
+	e.clipboardAsks = slices.Delete(e.clipboardAsks, i, i+1)
+	clipboard := e.clipboard
+	e.mu.Unlock()
+
+	if !allow {
+		clipboard = nil
+	}
+	e.replyClipboard(clipboard, selection)
+	return nil
+}
+
+// SetClipboard sets the clipboard OSC 52 writes are stored in and queries
+// are answered from. With no clipboard, writes only reach the callback set
+// with SetOnClipboard and queries are answered with an empty clipboard.
+func (e *Emulator) SetClipboard(clipboard Clipboard) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.clipboard = clipboard
+}
+
+// SetClipboardReadPolicy sets how OSC 52 clipboard queries are answered. The
+// default is ClipboardReadDeny.
+func (e *Emulator) SetClipboardReadPolicy(policy ClipboardReadPolicy) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.clipboardRead = policy
+}
+
+// SetOnClipboard sets a callback function that will be called when the child
+// copies text with OSC 52. It receives the emulator ID, the selection name and
+// the text, which is empty when the child clears the selection, and is called
+// from the read loop goroutine.
+func (e *Emulator) SetOnClipboard(callback func(id string, selection byte, text string)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onClipboard = callback
+}
+
+// SetOnClipboardRead sets a callback function that will be called when the
+// child queries the clipboard with OSC 52 under ClipboardReadAsk. It receives
+// the emulator ID and the selection name, and is called from the read loop
+// goroutine; answer the query with AnswerClipboardRead.
+func (e *Emulator) SetOnClipboardRead(callback func(id string, selection byte)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onClipboardRead = callback
+}
@@ -0,0 +1,105 @@
+package emulator
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// memClipboard is a Clipboard backed by a map.
+type memClipboard map[byte]string
+
+func (c memClipboard) WriteClipboard(selection byte, text string) error {
+	c[selection] = text
+	return nil
+}
+
+func (c memClipboard) ReadClipboard(selection byte) (string, error) {
+	return c[selection], nil
+}
+
+func TestClipboardWrite(t *testing.T) {
+	e := newDamageEmulator(t)
+	clipboard := memClipboard{}
+	e.SetClipboard(clipboard)
+
+	type write struct {
+		selection byte
+		text      string
+	}
+	var got []write
+	e.SetOnClipboard(func(id string, selection byte, text string) {
+		if id != e.ID() {
+			t.Errorf("callback got ID %q, want %q", id, e.ID())
+		}
+		got = append(got, write{selection, text})
+	})
+
+	feed(e, "\x1b]52;c;aGVsbG8=\x07")
+	feed(e, "\x1b]52;px;d29ybGQ=\x1b\\") // unknown selection names are skipped
+	feed(e, "\x1b]52;;Zm9v\x07")         // no name means the clipboard
+	if clipboard['c'] != "foo" || clipboard['p'] != "world" {
+		t.Fatalf("clipboard %q", clipboard)
+	}
+
+	feed(e, "\x1b]52;p;!\x07") // invalid base64 clears
+	if text, ok := clipboard['p']; !ok || text != "" {
+		t.Fatalf("primary %q after clearing, want empty", text)
+	}
+
+	want := []write{{'c', "hello"}, {'p', "world"}, {'c', "foo"}, {'p', ""}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("callback writes %v, want %v", got, want)
+	}
+}
+
+func TestClipboardReadPolicy(t *testing.T) {
+	e, input := newInputEmulator(t)
+	e.SetClipboard(memClipboard{'c': "secret"})
+
+	// Queries are denied by default.
+	feed(e, "\x1b]52;c;?\x07")
+	if got := collectInput(input); !slices.Equal(got, []string{"\x1b]52;c;\x07"}) {
+		t.Fatalf("denied query replied %q", got)
+	}
+
+	e.SetClipboardReadPolicy(ClipboardReadAllow)
+	feed(e, "\x1b]52;c;?\x07")
+	if got := collectInput(input); !slices.Equal(got, []string{"\x1b]52;c;c2VjcmV0\x07"}) {
+		t.Fatalf("allowed query replied %q", got)
+	}
+}
+
+func TestClipboardReadAsk(t *testing.T) {
+	e, input := newInputEmulator(t)
+	e.SetClipboard(memClipboard{'c': "secret"})
+	e.SetClipboardReadPolicy(ClipboardReadAsk)
+
+	var asked []byte
+	e.SetOnClipboardRead(func(id string, selection byte) {
+		asked = append(asked, selection)
+	})
+
+	feed(e, "\x1b]52;c;?\x07\x1b]52;p;?\x07")
+	if string(asked) != "cp" {
+		t.Fatalf("asked for %q, want \"cp\"", asked)
+	}
+	if got := collectInput(input); len(got) != 0 {
+		t.Fatalf("replied %q before the query was answered", got)
+	}
+
+	if err := e.AnswerClipboardRead('c', true); err != nil {
+		t.Fatalf("AnswerClipboardRead failed: %v", err)
+	}
+	if err := e.AnswerClipboardRead('p', false); err != nil {
+		t.Fatalf("AnswerClipboardRead failed: %v", err)
+	}
+	want := []string{"\x1b]52;c;c2VjcmV0\x07", "\x1b]52;p;\x07"}
+	if got := collectInput(input); !slices.Equal(got, want) {
+		t.Fatalf("answers replied %q, want %q", got, want)
+	}
+
+	if err := e.AnswerClipboardRead('c', true); !errors.Is(err, ErrNoClipboardRequest) {
+		t.Fatalf("answering twice returned %v, want ErrNoClipboardRequest", err)
+	}
+}
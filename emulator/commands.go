@@ -0,0 +1,241 @@
+package emulator
+
+import (
+	"bytes"
+	"slices"
+	"strconv"
+	"strings"
+
+	uv "github.com/charmbracelet/ultraviolet"
+)
+
+// maxCommands is the number of shell commands kept. Older ones are dropped
+// first, as are commands whose lines have left the scrollback.
+const maxCommands = 1000
+
+// Command is a shell command delimited by OSC 133 shell integration marks.
+// Lines are indexes into the terminal's history as of the Commands call:
+// lines below ScrollbackLen are scrollback lines (see ScrollbackLine) and
+// ScrollbackLen+y is screen row y.
+type Command struct {
+	PromptLine  int    // Line the prompt starts on (mark A), or -1 when not marked or dropped
+	CommandLine string // Command the user entered
+	OutputStart int    // First line of output still kept (mark C), or -1 before it runs
+	OutputEnd   int    // Line after the last line of output (mark D), or -1 until it finishes
+	ExitCode    int    // Exit status reported with mark D, or -1
+	Finished    bool   // Whether mark D was seen
+}
+
+// shellCommand is a Command with its marks anchored to absolute history lines
+// (see historyLine), which stay valid as lines scroll into the scrollback.
+type shellCommand struct {
+	prompt, output, end int // -1 until marked
+
+	// Where the command line starts (mark B), or -1 until marked
+	inputLine, inputCol int
+
+	commandLine string
+	explicit    bool // commandLine was set by OSC 633 E
+	exitCode    int
+	finished    bool
+}
+
+// registerCommandHandlers hooks the vt parser to record shell integration
+// marks: OSC 133 (FinalTerm) A, B, C and D, and the same marks plus E for the
+// command line in the OSC 633 dialect.
+func (e *Emulator) registerCommandHandlers() {
+	for _, cmd := range []int{133, 633} {
+		e.vt.RegisterOscHandler(cmd, func(data []byte) bool {
+			parts := bytes.Split(data, []byte{';'})
+			if len(parts) < 2 || len(parts[1]) != 1 || e.vt.IsAltScreen() {
+				return true
+			}
+			e.commandMark(parts[1][0], parts[2:])
+			return true
+		})
+	}
+}
+
+// commandMark records shell integration mark kind at the cursor. Must be
+// called with mu held.
+func (e *Emulator) commandMark(kind byte, args [][]byte) {
+	pos := e.vt.CursorPosition()
+	line := e.historyLine(pos.Y)
+
+	if kind == 'A' {
+		e.startCommand(line)
+		return
+	}
+	if kind < 'B' || kind > 'E' {
+		return
+	}
+	if kind == 'D' && !e.commandRunning() {
+		// Shells send D before every prompt, including the first one.
+		return
+	}
+	c := e.currentCommand()
+	switch kind {
+	case 'B':
+		c.inputLine, c.inputCol = line, pos.X
+	case 'C':
+		c.output = line
+		if !c.explicit && c.inputLine >= 0 {
+			c.commandLine = e.historyText(c.inputLine, c.inputCol, line, pos.X)
+		}
+	case 'D':
+		c.end = line
+		if pos.X > 0 {
+			c.end++ // the output did not end with a newline
+		}
+		if len(args) > 0 {
+			if code, err := strconv.Atoi(string(args[0])); err == nil {
+				c.exitCode = code
+			}
+		}
+		c.finished = true
+	case 'E':
+		// E ; command line [; nonce]
+		if len(args) > 0 {
+			c.commandLine = unescapeCommandLine(string(args[0]))
+			c.explicit = true
+		}
+	}
+}
+
+// startCommand begins a new command whose prompt starts on line, dropping
+// commands that left the scrollback. Must be called with mu held.
+func (e *Emulator) startCommand(line int) {
+	e.commands = slices.DeleteFunc(e.commands, e.commandDropped)
+	if len(e.commands) >= maxCommands {
+		e.commands = e.commands[len(e.commands)-maxCommands+1:]
+	}
+	e.commands = append(e.commands, &shellCommand{
+		prompt: line, output: -1, end: -1,
+		inputLine: -1, inputCol: -1,
+		exitCode: -1,
+	})
+}
+
+// commandDropped reports whether all lines of c have left the scrollback.
+// Must be called with mu held.
+func (e *Emulator) commandDropped(c *shellCommand) bool {
+	return max(c.prompt, c.inputLine, c.output, c.end) < e.historyStart()
+}
+
+// commandRunning reports whether the last command has not finished. Must be
+// called with mu held.
+func (e *Emulator) commandRunning() bool {
+	n := len(e.commands)
+	return n > 0 && !e.commands[n-1].finished
+}
+
+// currentCommand returns the command marks without a prompt apply to,
+// starting one without a prompt mark if the last command finished. Must be
+// called with mu held.
+func (e *Emulator) currentCommand() *shellCommand {
+	if e.commandRunning() {
+		return e.commands[len(e.commands)-1]
+	}
+	e.startCommand(-1)
+	return e.commands[len(e.commands)-1]
+}
+
+// historyText returns the text from (fromLine, fromCol) up to but excluding
+// (toLine, toCol), with lines trimmed of trailing blanks and joined without
+// separators, since a command line wraps rather than breaks. Must be called
+// with mu held.
+func (e *Emulator) historyText(fromLine, fromCol, toLine, toCol int) string {
+	var b strings.Builder
+	for line := fromLine; line <= toLine; line++ {
+		cells := e.historyCells(line)
+		start, end := 0, len(cells)
+		if line == fromLine {
+			start = fromCol
+		}
+		if line == toLine {
+			end = min(end, toCol)
+		}
+		var text strings.Builder
+		for x := start; x < end; x++ {
+			text.WriteString(cells[x].Content)
+		}
+		b.WriteString(strings.TrimRight(text.String(), " "))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// historyCells returns the cells of absolute history line, or nil when it
+// has left the scrollback. Must be called with mu held.
+func (e *Emulator) historyCells(line int) []uv.Cell {
+	i := e.scrollbackIndex(line)
+	if i < 0 {
+		return nil
+	}
+	if n := e.vt.Scrollback().Len(); i < n {
+		return e.vt.Scrollback().Line(i)
+	} else if y := i - n; y < e.height {
+		return e.lineCells(y)
+	}
+	return nil
+}
+
+// unescapeCommandLine decodes an OSC 633 E command line, in which '\' and
+// ';' and control characters are sent as \\ and \xAB escapes.
+func unescapeCommandLine(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		if s[i+1] == '\\' {
+			b.WriteByte('\\')
+			i++
+			continue
+		}
+		if s[i+1] == 'x' && i+3 < len(s) {
+			if c, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte('\\')
+	}
+	return b.String()
+}
+
+// Commands returns the shell commands recorded from shell integration marks,
+// oldest first. Commands whose lines have all left the scrollback are not
+// included.
+func (e *Emulator) Commands() []Command {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.syncScrollback()
+
+	index := func(line int) int {
+		if line < 0 {
+			return -1
+		}
+		return max(e.scrollbackIndex(line), 0)
+	}
+	var commands []Command
+	for _, c := range e.commands {
+		if e.commandDropped(c) {
+			continue
+		}
+		prompt := c.prompt
+		if prompt >= 0 && e.scrollbackIndex(prompt) < 0 {
+			prompt = -1 // dropped from the scrollback
+		}
+		commands = append(commands, Command{
+			PromptLine:  index(prompt),
+			CommandLine: c.commandLine,
+			OutputStart: index(c.output),
+			OutputEnd:   index(c.end),
+			ExitCode:    c.exitCode,
+			Finished:    c.finished,
+		})
+	}
+	return commands
+}
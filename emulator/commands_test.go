@@ -0,0 +1,73 @@
+package emulator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newCommandEmulator(t *testing.T) *Emulator {
+	t.Helper()
+	e, err := New(20, 4)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { e.Close() })
+	return e
+}
+
+func TestCommands(t *testing.T) {
+	e := newCommandEmulator(t)
+
+	feed(e, "\x1b]133;D\x07") // before the first prompt
+	feed(e, "\x1b]133;A\x07$ \x1b]133;B\x07echo hi\r\n\x1b]133;C\x07hi\r\n\x1b]133;D;0\x07")
+	feed(e, "\x1b]133;A\x07$ \x1b]133;B\x07false\r\n\x1b]133;C\x07\x1b]133;D;1\x07")
+	feed(e, "\x1b]133;A\x07$ ")
+
+	want := []Command{
+		{PromptLine: 0, CommandLine: "echo hi", OutputStart: 1, OutputEnd: 2, ExitCode: 0, Finished: true},
+		{PromptLine: 2, CommandLine: "false", OutputStart: 3, OutputEnd: 3, ExitCode: 1, Finished: true},
+		{PromptLine: 3, OutputStart: -1, OutputEnd: -1, ExitCode: -1},
+	}
+	if got := e.Commands(); !slices.Equal(got, want) {
+		t.Fatalf("commands %+v, want %+v", got, want)
+	}
+
+	// Lines keep pointing at the same text as it scrolls into the
+	// scrollback.
+	feed(e, strings.Repeat("x\r\n", 6))
+	if got := e.Commands(); !slices.Equal(got, want) {
+		t.Fatalf("commands after scrolling %+v, want %+v", got, want)
+	}
+	if got := e.ScrollbackLine(0)[2].Content; got != "e" {
+		t.Fatalf("prompt line holds %q, want the command", got)
+	}
+
+	// Commands whose lines left the scrollback are dropped.
+	if err := e.SetScrollbackSize(3); err != nil {
+		t.Fatalf("SetScrollbackSize failed: %v", err)
+	}
+	want = []Command{
+		{PromptLine: -1, CommandLine: "false", OutputStart: 0, OutputEnd: 0, ExitCode: 1, Finished: true},
+		{PromptLine: 0, OutputStart: -1, OutputEnd: -1, ExitCode: -1},
+	}
+	if got := e.Commands(); !slices.Equal(got, want) {
+		t.Fatalf("commands after shrinking the scrollback %+v, want %+v", got, want)
+	}
+}
+
+func TestCommandsExplicitCommandLine(t *testing.T) {
+	e := newCommandEmulator(t)
+
+	feed(e, "\x1b]633;A\x07> \x1b]633;B\x07\x1b]633;E;ls\\x3b pwd \\\\;nonce\x07ls; pwd \\\r\n\x1b]633;C\x07")
+	got := e.Commands()
+	if len(got) != 1 || got[0].CommandLine != `ls; pwd \` || got[0].OutputStart != 1 || got[0].Finished {
+		t.Fatalf("commands %+v", got)
+	}
+
+	// Marks are not recorded on the alternate screen.
+	feed(e, "\x1b[?1049h\x1b]633;D;0\x07\x1b[?1049l")
+	if got := e.Commands(); got[0].Finished {
+		t.Fatal("recorded a mark made on the alternate screen")
+	}
+}
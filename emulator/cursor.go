@@ -0,0 +1,79 @@
+package emulator
+
+import (
+	"bytes"
+	"image/color"
+)
+
+// defaultCursorState returns the cursor state of a freshly reset terminal: a
+// visible, blinking block in the host's default color.
+func defaultCursorState() CursorState {
+	return CursorState{Visible: true, Shape: CursorBlock, Blink: true}
+}
+
+// registerCursorHandlers hooks the vt parser to keep e.cursor in sync with
+// what the vt callbacks do not report.
+func (e *Emulator) registerCursorHandlers() {
+	// OSC 112 and a bare OSC 12 reset the cursor color. The vt emulator
+	// reports the reset as its own default color, which would hide that the
+	// child no longer asks for a specific color.
+	for _, cmd := range []int{12, 112} {
+		e.vt.RegisterOscHandler(cmd, func(data []byte) bool {
+			if bytes.IndexByte(data, ';') >= 0 {
+				return false
+			}
+			e.resetCursorColor()
+			return true
+		})
+	}
+
+	// RIS (ESC c) resets the cursor without firing callbacks.
+	e.vt.RegisterEscHandler('c', func() bool {
+		e.cursor = defaultCursorState()
+		e.resetCursorColor()
+		return false
+	})
+}
+
+// resetCursorColor drops the cursor color set by the child.
+// Must be called with mu held.
+func (e *Emulator) resetCursorColor() {
+	e.vt.SetCursorColor(nil)
+	e.cursor.Color = nil
+}
+
+// cursorState returns the current cursor state. Must be called with mu held.
+func (e *Emulator) cursorState() CursorState {
+	cur := e.cursor
+	pos := e.vt.CursorPosition()
+	cur.Pos = Pos{X: pos.X, Y: pos.Y}
+	if cur.Color == nil {
+		cur.Color = e.defaultCursorColor
+	}
+	return cur
+}
+
+// CursorState returns the cursor position together with its visibility, shape,
+// blink state and color as last set by the child.
+func (e *Emulator) CursorState() CursorState {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.cursorState()
+}
+
+// sameCursor reports whether two cursor states are identical.
+func sameCursor(a, b CursorState) bool {
+	return a.Pos == b.Pos && a.Visible == b.Visible && a.Shape == b.Shape &&
+		a.Blink == b.Blink && sameColor(a.Color, b.Color)
+}
+
+// sameColor reports whether two colors are identical, treating nil as a
+// distinct "default" color.
+func sameColor(a, b color.Color) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
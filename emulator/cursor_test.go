@@ -0,0 +1,103 @@
+package emulator
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestCursorVisibility(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "\x1b[?25l")
+	if _, visible := e.Cursor(); visible {
+		t.Fatal("expected cursor hidden after DECTCEM reset")
+	}
+	if e.CursorState().Visible {
+		t.Fatal("expected CursorState().Visible to be false")
+	}
+
+	feed(e, "\x1b[?25h")
+	if _, visible := e.Cursor(); !visible {
+		t.Fatal("expected cursor visible after DECTCEM set")
+	}
+}
+
+func TestCursorStyle(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	tests := []struct {
+		seq   string
+		shape CursorShape
+		blink bool
+	}{
+		{"\x1b[0 q", CursorBlock, true},
+		{"\x1b[2 q", CursorBlock, false},
+		{"\x1b[3 q", CursorUnderline, true},
+		{"\x1b[4 q", CursorUnderline, false},
+		{"\x1b[5 q", CursorBar, true},
+		{"\x1b[6 q", CursorBar, false},
+	}
+	for _, tt := range tests {
+		feed(e, tt.seq)
+		cur := e.CursorState()
+		if cur.Shape != tt.shape || cur.Blink != tt.blink {
+			t.Errorf("%q: got shape %v blink %v, want %v %v", tt.seq, cur.Shape, cur.Blink, tt.shape, tt.blink)
+		}
+	}
+}
+
+func TestCursorColor(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	if c := e.CursorState().Color; c != nil {
+		t.Fatalf("expected default cursor color, got %v", c)
+	}
+
+	feed(e, "\x1b]12;rgb:ff/00/00\x07")
+	if !sameColor(e.CursorState().Color, color.RGBA{R: 0xff, A: 0xff}) {
+		t.Fatalf("expected red cursor, got %v", e.CursorState().Color)
+	}
+
+	feed(e, "\x1b]112\x07")
+	if c := e.CursorState().Color; c != nil {
+		t.Fatalf("expected cursor color reset, got %v", c)
+	}
+}
+
+func TestCursorResetByRIS(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "\x1b[?25l\x1b[6 q\x1b]12;rgb:00/ff/00\x07")
+	feed(e, "\x1bc")
+	if cur := e.CursorState(); !sameCursor(cur, defaultCursorState()) {
+		t.Fatalf("expected default cursor after RIS, got %+v", cur)
+	}
+}
+
+func TestGetScreenCarriesCursor(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "ab")
+	frame := e.GetScreen()
+	if !frame.CursorChanged {
+		t.Fatal("expected CursorChanged after the cursor moved")
+	}
+	if frame.Cursor.Pos != (Pos{X: 2, Y: 0}) || !frame.Cursor.Visible {
+		t.Fatalf("unexpected cursor %+v", frame.Cursor)
+	}
+
+	// Hiding the cursor changes no row but must still be reported.
+	feed(e, "\x1b[?25l")
+	frame = e.GetScreen()
+	if len(frame.Damage) != 0 {
+		t.Fatalf("expected no row damage, got %+v", frame.Damage)
+	}
+	if !frame.CursorChanged || frame.Cursor.Visible {
+		t.Fatalf("expected hidden cursor change, got %+v", frame)
+	}
+
+	frame = e.GetScreen()
+	if frame.CursorChanged || frame.Cursor.Visible {
+		t.Fatalf("expected cached hidden cursor, got %+v", frame)
+	}
+}
@@ -0,0 +1,153 @@
+package emulator
+
+import (
+	"maps"
+	"slices"
+
+	uv "github.com/charmbracelet/ultraviolet"
+)
+
+// damageAll marks every row as damaged for reason on the next GetScreen call,
+// overriding the per-row diff. A screen switch is never masked by a later
+// reason in the same frame, so consumers always see it. Must be called with
+// mu held.
+func (e *Emulator) damageAll(reason ChangeReason) {
+	if !e.fullDamage || e.fullReason != CRScreenSwitch {
+		e.fullReason = reason
+	}
+	e.fullDamage = true
+	e.markDamaged()
+}
+
+// RedrawAll forces the next GetScreen call to report every row as damaged
+// with reason CRRedraw, e.g. after the consumer lost its own copy of the
+// frame.
+func (e *Emulator) RedrawAll() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.damageAll(CRRedraw)
+}
+
+// maxScrollCandidates bounds how many earlier rows a changed row is matched
+// against when detecting a scroll, so that screens full of identical rows
+// cannot make detection quadratic.
+const maxScrollCandidates = 4
+
+// diffRows returns the damage for each row renderRows redrew, with the columns
+// vt touched. Rows that were touched but render the same as before are not
+// reported. Must be called with mu held.
+func (e *Emulator) diffRows(rows []string, changes []rowChange) []LineDamage {
+	if e.fullDamage || len(e.lastRows) != len(rows) {
+		reason := e.fullReason
+		if !e.fullDamage {
+			reason = CRRedraw
+		}
+		e.fullDamage = false
+		damage := make([]LineDamage, len(rows))
+		for y := range rows {
+			damage[y] = LineDamage{Row: y, X1: 0, X2: e.width, Reason: reason}
+		}
+		return damage
+	}
+
+	shift := scrollShift(rows, e.lastRows, changes)
+	var damage []LineDamage
+	for y, c := range changes {
+		if !c.rendered || rows[y] == e.lastRows[y] {
+			continue
+		}
+		x1, x2 := c.x1, c.x2
+		if x1 >= x2 {
+			// No touched span (e.g. only the rendering changed); report
+			// the whole row.
+			x1, x2 = 0, e.width
+		}
+		damage = append(damage, LineDamage{
+			Row:    y,
+			X1:     x1,
+			X2:     x2,
+			Reason: rowReason(y, shift, rows, e.lastRows, c.blank),
+		})
+	}
+	return damage
+}
+
+// rowReason classifies the change to row y given the detected scroll shift.
+func rowReason(y, shift int, rows, lastRows []string, blank bool) ChangeReason {
+	if shift != 0 {
+		if src := y + shift; src >= 0 && src < len(lastRows) && rows[y] == lastRows[src] {
+			return CRScroll
+		}
+	}
+	if blank {
+		// Rows exposed at the edge of a scroll come in blank.
+		if (shift > 0 && y >= len(rows)-shift) || (shift < 0 && y < -shift) {
+			return CRScroll
+		}
+		return CRClear
+	}
+	return CRText
+}
+
+// scrollShift detects whether the screen content moved vertically since the
+// previous frame. It returns the most common offset d such that a changed row
+// y now shows what row y+d showed before: positive when content scrolled up,
+// negative when it scrolled down, and 0 when no movement was found. Only
+// redrawn rows vote, and only when at least two of them changed, since a
+// scroll touches every row it moves. Blank rows match anywhere, so they do not
+// vote.
+func scrollShift(rows, lastRows []string, changes []rowChange) int {
+	changed := 0
+	for y, c := range changes {
+		if c.rendered && !c.blank && rows[y] != lastRows[y] {
+			changed++
+		}
+	}
+	if changed < 2 {
+		return 0
+	}
+
+	index := make(map[string][]int, len(lastRows))
+	for y, row := range lastRows {
+		if len(index[row]) < maxScrollCandidates {
+			index[row] = append(index[row], y)
+		}
+	}
+
+	votes := map[int]int{}
+	for y, c := range changes {
+		if !c.rendered || c.blank || rows[y] == lastRows[y] {
+			continue
+		}
+		for _, src := range index[rows[y]] {
+			votes[src-y]++
+		}
+	}
+
+	// Ties go to the smaller offset and, between d and -d, to scrolling up,
+	// so the same frames always yield the same damage.
+	shift, best := 0, 0
+	for _, d := range slices.Sorted(maps.Keys(votes)) {
+		if n := votes[d]; n > best || (n == best && abs(d) <= abs(shift)) {
+			shift, best = d, n
+		}
+	}
+	return shift
+}
+
+// isBlankLine reports whether every cell of the line is empty or a space.
+func isBlankLine(cells []uv.Cell) bool {
+	for _, c := range cells {
+		if c.Content != "" && c.Content != " " {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
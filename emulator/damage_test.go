@@ -0,0 +1,197 @@
+package emulator
+
+import (
+	"testing"
+)
+
+// feed writes s through the vt emulator the way ptyReadLoop does.
+func feed(e *Emulator, s string) {
+	e.mu.Lock()
+	e.write([]byte(s))
+	events := e.takeEvents()
+	e.mu.Unlock()
+	runEvents(events)
+}
+
+// newDamageEmulator returns a 10x4 emulator whose initial frame has been
+// consumed.
+func newDamageEmulator(t *testing.T) *Emulator {
+	t.Helper()
+	e, err := New(10, 4)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { e.Close() })
+	e.GetScreen()
+	return e
+}
+
+func damageByRow(damage []LineDamage) map[int]LineDamage {
+	rows := make(map[int]LineDamage, len(damage))
+	for _, d := range damage {
+		rows[d.Row] = d
+	}
+	return rows
+}
+
+func TestGetScreenInitialFrameIsRedraw(t *testing.T) {
+	e, err := New(10, 4)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Close()
+
+	frame := e.GetScreen()
+	if len(frame.Damage) != 4 {
+		t.Fatalf("expected 4 damaged rows, got %d", len(frame.Damage))
+	}
+	for _, d := range frame.Damage {
+		if d.Reason != CRRedraw || d.X1 != 0 || d.X2 != 10 {
+			t.Fatalf("unexpected initial damage %+v", d)
+		}
+	}
+}
+
+func TestGetScreenDamagesOnlyChangedSpan(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "\x1b[2;4Hab")
+	frame := e.GetScreen()
+	if len(frame.Damage) != 1 {
+		t.Fatalf("expected 1 damaged row, got %+v", frame.Damage)
+	}
+	want := LineDamage{Row: 1, X1: 3, X2: 5, Reason: CRText}
+	if frame.Damage[0] != want {
+		t.Fatalf("damage = %+v, want %+v", frame.Damage[0], want)
+	}
+}
+
+func TestGetScreenReportsClear(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "hello\r\nworld")
+	e.GetScreen()
+
+	feed(e, "\x1b[2K")
+	frame := e.GetScreen()
+	if len(frame.Damage) != 1 {
+		t.Fatalf("expected 1 damaged row, got %+v", frame.Damage)
+	}
+	// EL clears, and so touches, the whole row.
+	want := LineDamage{Row: 1, X1: 0, X2: 10, Reason: CRClear}
+	if frame.Damage[0] != want {
+		t.Fatalf("damage = %+v, want %+v", frame.Damage[0], want)
+	}
+}
+
+func TestGetScreenReportsScroll(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "a\r\nb\r\nc\r\nd")
+	e.GetScreen()
+
+	feed(e, "\r\n")
+	frame := e.GetScreen()
+	rows := damageByRow(frame.Damage)
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 damaged rows, got %+v", frame.Damage)
+	}
+	for y := range 4 {
+		if rows[y].Reason != CRScroll {
+			t.Fatalf("row %d reason = %v, want CRScroll", y, rows[y].Reason)
+		}
+	}
+}
+
+func TestScrollShiftBreaksTiesUp(t *testing.T) {
+	// Moving up and down one row match as many rows each.
+	lastRows := []string{"a", "b", "a"}
+	rows := []string{"b", "a", "b"}
+	changes := make([]rowChange, len(rows))
+	for y := range changes {
+		changes[y] = rowChange{rendered: true, x2: 1}
+	}
+	for range 20 {
+		if shift := scrollShift(rows, lastRows, changes); shift != 1 {
+			t.Fatalf("scrollShift = %d, want 1", shift)
+		}
+	}
+}
+
+func TestGetScreenReportsScreenSwitch(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "main")
+	e.GetScreen()
+
+	feed(e, "\x1b[?1049h")
+	frame := e.GetScreen()
+	if len(frame.Damage) != 4 {
+		t.Fatalf("expected 4 damaged rows, got %+v", frame.Damage)
+	}
+	for _, d := range frame.Damage {
+		if d.Reason != CRScreenSwitch {
+			t.Fatalf("row %d reason = %v, want CRScreenSwitch", d.Row, d.Reason)
+		}
+	}
+}
+
+func TestScreenSwitchNotMaskedByRedraw(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "\x1b[?1049h")
+	if !e.IsAltScreen() {
+		t.Fatal("expected the alternate screen after DECSET 1049")
+	}
+	e.RedrawAll()
+	frame := e.GetScreen()
+	if len(frame.Damage) != 4 || frame.Damage[0].Reason != CRScreenSwitch {
+		t.Fatalf("expected full CRScreenSwitch damage, got %+v", frame.Damage)
+	}
+
+	feed(e, "\x1b[?1049l")
+	if e.IsAltScreen() {
+		t.Fatal("expected the main screen after DECRST 1049")
+	}
+	if frame := e.GetScreen(); len(frame.Damage) != 4 || frame.Damage[0].Reason != CRScreenSwitch {
+		t.Fatalf("expected full CRScreenSwitch damage, got %+v", frame.Damage)
+	}
+}
+
+func TestGetScreenReportsRedraw(t *testing.T) {
+	t.Run("RedrawAll", func(t *testing.T) {
+		e := newDamageEmulator(t)
+		e.RedrawAll()
+		frame := e.GetScreen()
+		if len(frame.Damage) != 4 || frame.Damage[0].Reason != CRRedraw {
+			t.Fatalf("expected full CRRedraw damage, got %+v", frame.Damage)
+		}
+	})
+
+	t.Run("RIS", func(t *testing.T) {
+		e := newDamageEmulator(t)
+		feed(e, "text")
+		e.GetScreen()
+		feed(e, "\x1bc")
+		frame := e.GetScreen()
+		if len(frame.Damage) != 4 || frame.Damage[0].Reason != CRRedraw {
+			t.Fatalf("expected full CRRedraw damage, got %+v", frame.Damage)
+		}
+	})
+
+	t.Run("Resize", func(t *testing.T) {
+		e := newDamageEmulator(t)
+		if err := e.Resize(12, 3); err != nil {
+			t.Fatalf("Resize failed: %v", err)
+		}
+		frame := e.GetScreen()
+		if len(frame.Damage) != 3 {
+			t.Fatalf("expected 3 damaged rows, got %+v", frame.Damage)
+		}
+		for _, d := range frame.Damage {
+			if d.Reason != CRRedraw || d.X2 != 12 {
+				t.Fatalf("unexpected resize damage %+v", d)
+			}
+		}
+	})
+}
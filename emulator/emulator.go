@@ -2,12 +2,14 @@ package emulator
 
 import (
 	"fmt"
+	"image/color"
 	"io"
 	"os"
 	"os/exec"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	uv "github.com/charmbracelet/ultraviolet"
 	"github.com/charmbracelet/x/ansi"
@@ -37,16 +39,99 @@ type Emulator struct {
 
 	// Process tracking
 	cmd           *exec.Cmd
+	startTime     time.Time
 	processExited bool
-	onExit        func(string) // Callback when process exits, receives emulator ID
+	exited        chan struct{} // closed when the process exits
+	exitStatus    ExitStatus
+	onExit        func(string)             // Callback when process exits, receives emulator ID
+	onExitStatus  func(string, ExitStatus) // Like onExit, with the exit status
+	onForeground  func(string, Process)    // Callback when the foreground process changes
 
 	stopChan chan struct{}
 
+	// Terminal modes as set by the child, mirrored from vt callbacks
+	modes ansi.Modes
+
+	// Cursor appearance as set by the child; Pos is filled in on read
+	cursor CursorState
+
+	// Window title and icon name (OSC 0/1/2) and the XTWINOPS title stack
+	title, iconName string
+	titleStack      []titleEntry
+	onTitle         func(id, title string)
+
+	// Bells rung and the bell and desktop notification callbacks
+	bells    int
+	onBell   func(id string)
+	onNotify func(id string, n Notification)
+
+	// Progress last reported with OSC 9;4 and the progress callback
+	progressState   ProgressState
+	progressPercent int
+	onProgress      func(id string, state ProgressState, percent int)
+
+	// Colors configured with SetPalette and SetDefaultColors and set by the
+	// child with OSC 4, 10 and 11; nil colors are left to the host terminal
+	palette            [256]color.Color
+	childPalette       [256]color.Color
+	defaultFg          color.Color
+	defaultBg          color.Color
+	defaultCursorColor color.Color
+	childFg, childBg   color.Color
+	themed             bool // some color is not left to the host terminal
+
+	// Working directory last reported with OSC 7
+	workingDir string
+
+	// OSC 8 hyperlink being written, and the cell under the cursor when it
+	// last moved with its absolute history line and column
+	link              uv.Link
+	linkCell          uv.Cell
+	linkLine, linkCol int
+
+	// Kitty keyboard protocol flags of the main and alternate screens
+	kitty [2]kittyKeyboard
+
+	// OSC 52 clipboard backend, read policy and callbacks, and the
+	// selections of queries waiting for AnswerClipboardRead
+	clipboard       Clipboard
+	clipboardRead   ClipboardReadPolicy
+	clipboardAsks   []byte
+	onClipboard     func(id string, selection byte, text string)
+	onClipboardRead func(id string, selection byte)
+
+	// Scrollback size as configured; the vt buffer is allowed to grow past
+	// it until syncScrollback trims it. scrolledLines counts every line that
+	// ever scrolled into the scrollback, and scrollbackSeen is its length at
+	// the last sync.
+	scrollbackSize int
+	scrolledLines  int
+	scrollbackSeen int
+
+	// Shell commands recorded from OSC 133 shell integration marks
+	commands []*shellCommand
+
+	// Mouse button held down, for reporting drags in button-event mode
+	mouseHeld vt.MouseButton
+
+	// Callbacks queued by vt handlers, run once mu is released
+	pendingEvents []func()
+
 	// Damage tracking for change detection
-	lastRender string
-	lastRows   []string
-	damaged    bool
-	notifyC    chan struct{} // signaled when new damage occurs
+	lastRows     []string
+	lastLines    [][]uv.Cell // cells of lastRows
+	lastScrolled int         // scrolledLines at the last frame
+	lastCursor   CursorState
+	damaged      bool
+	fullDamage   bool          // next GetScreen reports every row with fullReason
+	fullReason   ChangeReason  // why the whole screen is damaged
+	notifyC      chan struct{} // signaled when new damage occurs
+
+	// Synchronized output (mode 2026): while syncing, damage is held back
+	// until the child ends the update or syncTimer fires
+	syncing   bool
+	syncGen   int // identifies the current update to its timer
+	syncTimer *time.Timer
 
 	// Screen dimensions
 	width, height int
@@ -54,8 +139,10 @@ type Emulator struct {
 
 // EmittedFrame represents a rendered frame from the terminal.
 type EmittedFrame struct {
-	Rows   []string     // Each row is a string with ANSI escape codes embedded
-	Damage []LineDamage // Lines that changed since the last GetScreen call
+	Rows          []string     // Each row is a string with ANSI escape codes embedded
+	Damage        []LineDamage // Lines that changed since the last GetScreen call
+	Cursor        CursorState  // Cursor position and appearance
+	CursorChanged bool         // Cursor differs from the last GetScreen call
 }
 
 // New creates a new headless terminal emulator
@@ -65,10 +152,13 @@ func New(cols, rows int) (*Emulator, error) {
 		id:       uuid.New().String(),
 		stopChan: make(chan struct{}),
 		notifyC:  make(chan struct{}, 1),
+		modes:    defaultModes(),
+		cursor:   defaultCursorState(),
 		width:    cols,
 		height:   rows,
 		damaged:  true, // Initial render needed
 	}
+	e.setupVT()
 
 	var err error
 	e.pty, e.tty, err = pty.Open()
@@ -103,6 +193,8 @@ func NewFromPipes(cols, rows int, r io.Reader, w io.WriteCloser) (*Emulator, err
 		id:       uuid.New().String(),
 		stopChan: make(chan struct{}),
 		notifyC:  make(chan struct{}, 1),
+		modes:    defaultModes(),
+		cursor:   defaultCursorState(),
 		reader:   r,
 		writer:   w,
 		isPipe:   true,
@@ -110,6 +202,7 @@ func NewFromPipes(cols, rows int, r io.Reader, w io.WriteCloser) (*Emulator, err
 		height:   rows,
 		damaged:  true,
 	}
+	e.setupVT()
 
 	// Start the read loop using the provided reader and drain terminal
 	// responses (for queries like DA/DSR) back to the remote process.
@@ -119,6 +212,56 @@ func NewFromPipes(cols, rows int, r io.Reader, w io.WriteCloser) (*Emulator, err
 	return e, nil
 }
 
+// setupVT installs the callbacks and escape sequence handlers layered on top
+// of the vt emulator's defaults. It must run before the read loop starts.
+func (e *Emulator) setupVT() {
+	e.scrollbackSize = DefaultScrollbackSize
+	e.vt.SetScrollbackSize(DefaultScrollbackSize + scrollbackHeadroom)
+	e.vt.SetCallbacks(vt.Callbacks{
+		EnableMode: func(mode ansi.Mode) {
+			e.modes[mode] = ansi.ModeSet
+			if mode == ansi.ModeSynchronizedOutput {
+				e.setSyncOutput(true)
+			}
+		},
+		DisableMode: func(mode ansi.Mode) {
+			e.modes[mode] = ansi.ModeReset
+			if mode == ansi.ModeSynchronizedOutput {
+				e.setSyncOutput(false)
+			}
+		},
+		AltScreen:        func(bool) { e.damageAll(CRScreenSwitch) },
+		CursorPosition:   e.followLink,
+		CursorVisibility: func(visible bool) { e.cursor.Visible = visible },
+		CursorStyle: func(style vt.CursorStyle, steady bool) {
+			e.cursor.Shape = CursorShape(style)
+			e.cursor.Blink = !steady
+		},
+		CursorColor:      func(c color.Color) { e.cursor.Color = c },
+		WorkingDirectory: e.setWorkingDir,
+		Bell:             e.ringBell,
+	})
+	// RIS (ESC c) resets the whole screen; let the default handler do the
+	// reset and report a full redraw.
+	e.vt.RegisterEscHandler('c', func() bool {
+		e.damageAll(CRRedraw)
+		return false
+	})
+	e.registerModeHandlers()
+	e.registerCursorHandlers()
+	e.registerTitleHandlers()
+	e.registerScrollbackHandlers()
+	e.registerKittyHandlers()
+	e.registerClipboardHandlers()
+	e.registerLinkHandlers()
+	e.registerWorkingDirHandlers()
+	e.registerCommandHandlers()
+	e.registerNotifyHandlers()
+	e.registerProgressHandlers()
+	e.registerPaletteHandlers()
+	e.registerSyncHandlers()
+}
+
 func (e *Emulator) ID() string {
 	return e.id
 }
@@ -140,8 +283,8 @@ func (e *Emulator) resize(cols, rows int) error {
 		err := pty.Setsize(e.pty, &pty.Winsize{
 			Rows: uint16(rows),
 			Cols: uint16(cols),
-			X:    uint16(cols * 8),
-			Y:    uint16(rows * 16),
+			X:    uint16(cols * cellPixelWidth),
+			Y:    uint16(rows * cellPixelHeight),
 		})
 		if err != nil {
 			return err
@@ -151,15 +294,19 @@ func (e *Emulator) resize(cols, rows int) error {
 	e.vt.Resize(cols, rows)
 	e.width = cols
 	e.height = rows
-	e.markDamaged()
+	e.damageAll(CRRedraw)
 
 	return nil
 }
 
-// markDamaged sets the damaged flag and signals notifyC.
+// markDamaged sets the damaged flag and signals notifyC, unless a
+// synchronized update holds the signal back until it ends.
 // Must be called with mu held.
 func (e *Emulator) markDamaged() {
 	e.damaged = true
+	if e.syncing {
+		return
+	}
 	select {
 	case e.notifyC <- struct{}{}:
 	default:
@@ -168,37 +315,28 @@ func (e *Emulator) markDamaged() {
 
 // GetScreen returns the current rendered screen as ANSI strings.
 // It also returns damage information about which lines changed since
-// the last call. When nothing has changed since the last call, it
-// returns cached rows with empty Damage.
+// the last call, with the changed column span and the reason for each
+// row, and the cursor state. When nothing has changed since the last call,
+// it returns cached rows with empty Damage, as it does while the child is in
+// a synchronized update (mode 2026) so that half-drawn frames are not shown.
 func (e *Emulator) GetScreen() EmittedFrame {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if !e.damaged {
-		return EmittedFrame{Rows: e.lastRows}
+	// During a synchronized update, keep showing the last complete frame.
+	if !e.damaged || (e.syncing && e.lastRows != nil) {
+		return EmittedFrame{Rows: e.lastRows, Cursor: e.lastCursor}
 	}
 
-	rendered := e.vt.Render()
 	e.damaged = false
 
-	// Check for changes
-	var damage []LineDamage
-	if rendered != e.lastRender {
-		damage = make([]LineDamage, e.height)
-		for y := 0; y < e.height; y++ {
-			damage[y] = LineDamage{
-				Row:    y,
-				X1:     0,
-				X2:     e.width,
-				Reason: CRText,
-			}
-		}
-		e.lastRender = rendered
-	}
-
-	rows := splitIntoRows(rendered, e.height, e.width)
+	rows, changes := e.renderRows()
+	damage := e.diffRows(rows, changes)
+	cursor := e.cursorState()
+	cursorChanged := !sameCursor(cursor, e.lastCursor)
 	e.lastRows = rows
-	return EmittedFrame{Rows: rows, Damage: damage}
+	e.lastCursor = cursor
+	return EmittedFrame{Rows: rows, Damage: damage, Cursor: cursor, CursorChanged: cursorChanged}
 }
 
 // splitIntoRows splits the rendered output into individual rows and pads to width
@@ -224,12 +362,39 @@ func splitIntoRows(rendered string, height, width int) []string {
 // into the padding or into subsequent rows when rows are joined with \n.
 func padRow(row string, width int) string {
 	const reset = "\033[0m"
-	if visibleLen := ansi.StringWidth(row); visibleLen < width {
+	if visibleLen := rowWidth(row); visibleLen < width {
 		return row + reset + strings.Repeat(" ", width-visibleLen)
 	}
 	return row + reset
 }
 
+// rowWidth returns the visible width of a rendered row. Rows made of ASCII
+// text and CSI or OSC sequences, as most are, are measured without the
+// grapheme segmentation ansi.StringWidth does.
+func rowWidth(row string) int {
+	width := 0
+	for i := 0; i < len(row); i++ {
+		switch b := row[i]; {
+		case b == '\x1b' && i+1 < len(row) && row[i+1] == '[':
+			// CSI runs up to its final byte.
+			for i += 2; i < len(row) && (row[i] < 0x40 || row[i] > 0x7e); i++ {
+			}
+		case b == '\x1b' && i+1 < len(row) && row[i+1] == ']':
+			// OSC runs up to BEL or ST (ESC \).
+			for i += 2; i < len(row) && row[i] != '\a' && row[i] != '\x1b'; i++ {
+			}
+			if i < len(row) && row[i] == '\x1b' {
+				i++
+			}
+		case b < 0x20 || b >= 0x7f:
+			return ansi.StringWidth(row)
+		default:
+			width++
+		}
+	}
+	return width
+}
+
 // CellAt returns the cell at the given column (x) and row (y).
 // It returns nil if the position is out of bounds.
 func (e *Emulator) CellAt(x, y int) *uv.Cell {
@@ -243,16 +408,9 @@ func (e *Emulator) CellAt(x, y int) *uv.Cell {
 func (e *Emulator) GetCells() [][]uv.Cell {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	// TODO: replace per-cell loop with vt.Line(y) once charmbracelet/x/vt
-	// exposes it (Screen.buf is private).
 	cells := make([][]uv.Cell, e.height)
 	for y := range e.height {
-		cells[y] = make([]uv.Cell, e.width)
-		for x := range e.width {
-			if c := e.vt.CellAt(x, y); c != nil {
-				cells[y][x] = *c
-			}
-		}
+		cells[y] = e.lineCells(y)
 	}
 	return cells
 }
@@ -261,10 +419,8 @@ func (e *Emulator) GetCells() [][]uv.Cell {
 func (e *Emulator) Cursor() (Pos, bool) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	pos := e.vt.CursorPosition()
-	// The vt package doesn't expose cursor visibility directly in a simple way
-	// Default to visible
-	return Pos{X: pos.X, Y: pos.Y}, true
+	cur := e.cursorState()
+	return cur.Pos, cur.Visible
 }
 
 // SetOnExit sets a callback function that will be called when the process exits
@@ -329,37 +485,52 @@ func (e *Emulator) StartCommand(cmd *exec.Cmd) error {
 	// Store the command reference
 	e.cmd = cmd
 	e.processExited = false
+	e.exitStatus = ExitStatus{}
+	e.exited = nil
+	e.workingDir = "" // reported by the previous child
 
 	err := cmd.Start()
 	if err != nil {
 		return err
 	}
+	e.startTime = time.Now()
+	e.exited = make(chan struct{})
 
-	// Start monitoring the process in a goroutine
-	go e.monitorProcess()
+	// Start monitoring the process and its foreground jobs in goroutines
+	go e.monitorProcess(cmd, e.exited, e.startTime)
+	go e.watchForeground(e.exited)
 
 	return nil
 }
 
-// monitorProcess waits for the process to exit and calls the exit callback
-func (e *Emulator) monitorProcess() {
-	if e.cmd == nil {
-		return
-	}
-
+// monitorProcess waits for cmd to exit and closes exited. If cmd is still the
+// emulator's process, it records its exit status and calls the exit callbacks;
+// a process replaced by a later StartCommand exits silently.
+func (e *Emulator) monitorProcess(cmd *exec.Cmd, exited chan struct{}, start time.Time) {
 	// Wait for the process to exit
-	_ = e.cmd.Wait()
+	err := cmd.Wait()
+	status := exitStatusFrom(cmd, err, time.Since(start))
 
 	e.mu.Lock()
+	close(exited)
+	if e.cmd != cmd {
+		e.mu.Unlock()
+		return
+	}
 	e.processExited = true
+	e.exitStatus = status
 	onExit := e.onExit
+	onExitStatus := e.onExitStatus
 	id := e.id
 	e.mu.Unlock()
 
-	// Call the exit callback if set
+	// Call the exit callbacks if set
 	if onExit != nil {
 		onExit(id)
 	}
+	if onExitStatus != nil {
+		onExitStatus(id, status)
+	}
 }
 
 // Write sends data to the PTY or pipe (keyboard input)
@@ -387,7 +558,9 @@ func (e *Emulator) SendKey(key string) error {
 	return err
 }
 
-// SendMouse sends a mouse event to the terminal in SGR format
+// SendMouse sends a mouse event to the terminal. Buttons 0, 1 and 2 are left,
+// middle and right, and -1 is motion. Events the child's mouse tracking mode
+// does not ask for are dropped; see MouseMode.
 func (e *Emulator) SendMouse(button int, x, y int, pressed bool) error {
 	// Convert to the vt package's mouse event format
 	var vtButton vt.MouseButton
@@ -408,19 +581,19 @@ func (e *Emulator) SendMouse(button int, x, y int, pressed bool) error {
 	defer e.mu.Unlock()
 
 	if pressed {
-		e.vt.SendMouse(vt.MouseClick{
+		e.sendMouse(vt.MouseClick{
 			Button: vtButton,
 			X:      x,
 			Y:      y,
 		})
 	} else if button == -1 {
-		e.vt.SendMouse(vt.MouseMotion{
+		e.sendMouse(vt.MouseMotion{
 			Button: vtButton,
 			X:      x,
 			Y:      y,
 		})
 	} else {
-		e.vt.SendMouse(vt.MouseRelease{
+		e.sendMouse(vt.MouseRelease{
 			Button: vtButton,
 			X:      x,
 			Y:      y,
@@ -430,12 +603,13 @@ func (e *Emulator) SendMouse(button int, x, y int, pressed bool) error {
 	return nil
 }
 
-// SendMouseWheel sends a mouse wheel event to the terminal
+// SendMouseWheel sends a mouse wheel event to the terminal if the child has
+// enabled mouse tracking
 func (e *Emulator) SendMouseWheel(button int, x, y int) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	e.vt.SendMouse(vt.MouseWheel{
+	e.sendMouse(vt.MouseWheel{
 		Button: vt.MouseButton(button),
 		X:      x,
 		Y:      y,
@@ -444,6 +618,21 @@ func (e *Emulator) SendMouseWheel(button int, x, y int) error {
 	return nil
 }
 
+// SendFocus reports a focus change (CSI I / CSI O) to the terminal if the
+// child has enabled focus events (mode 1004)
+func (e *Emulator) SendFocus(focused bool) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if focused {
+		e.vt.Focus()
+	} else {
+		e.vt.Blur()
+	}
+
+	return nil
+}
+
 // Close shuts down the emulator
 func (e *Emulator) Close() error {
 	var closeErr error
@@ -529,6 +718,13 @@ func (e *Emulator) responseLoop() {
 	}
 }
 
+// write feeds child output to the vt emulator. Must be called with mu held.
+func (e *Emulator) write(p []byte) {
+	e.vt.Write(p)
+	e.syncScrollback()
+	e.markDamaged()
+}
+
 // ptyReadLoop reads from PTY/pipe and writes to the vt emulator
 func (e *Emulator) ptyReadLoop() {
 	var source io.Reader
@@ -553,9 +749,10 @@ func (e *Emulator) ptyReadLoop() {
 
 		if n > 0 {
 			e.mu.Lock()
-			e.vt.Write(buf[:n])
-			e.markDamaged()
+			e.write(buf[:n])
+			events := e.takeEvents()
 			e.mu.Unlock()
+			runEvents(events)
 		}
 	}
 }
@@ -3,6 +3,8 @@ package emulator
 import "errors"
 
 var (
-	ErrPTYNotInitialized = errors.New("PTY not initialized")
-	ErrInvalidSize       = errors.New("invalid terminal size")
+	ErrPTYNotInitialized  = errors.New("PTY not initialized")
+	ErrInvalidSize        = errors.New("invalid terminal size")
+	ErrNoProcess          = errors.New("no process started")
+	ErrNoClipboardRequest = errors.New("no pending clipboard request")
 )
@@ -0,0 +1,24 @@
+package emulator
+
+// queueEvent schedules fn to run once mu is released. vt callbacks and escape
+// sequence handlers run inside vt.Write with mu held, so user callbacks are
+// queued from there instead of being called directly, letting them call back
+// into the Emulator. Must be called with mu held.
+func (e *Emulator) queueEvent(fn func()) {
+	e.pendingEvents = append(e.pendingEvents, fn)
+}
+
+// takeEvents returns and clears the queued events. Must be called with mu
+// held; run the returned functions after releasing it.
+func (e *Emulator) takeEvents() []func() {
+	events := e.pendingEvents
+	e.pendingEvents = nil
+	return events
+}
+
+// runEvents calls the queued events in order. Must be called without mu held.
+func runEvents(events []func()) {
+	for _, fn := range events {
+		fn()
+	}
+}
@@ -0,0 +1,165 @@
+package emulator
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// foregroundPollInterval is how often the foreground process group is checked
+// for changes while the started process runs.
+const foregroundPollInterval = 250 * time.Millisecond
+
+// clockTicks is USER_HZ, the unit of process start times in /proc/<pid>/stat,
+// which Linux fixes at 100 for user space.
+const clockTicks = 100
+
+// Process describes a process running in the terminal.
+type Process struct {
+	Pid       int
+	Name      string   // Command name, as in /proc/<pid>/comm
+	Args      []string // Command line, with the program as Args[0]
+	StartTime time.Time
+}
+
+// ForegroundProcess returns the leader of the PTY's foreground process group:
+// the job the user is interacting with, such as the shell or a program it
+// started. It returns ErrNoProcess if there is none.
+func (e *Emulator) ForegroundProcess() (Process, error) {
+	e.mu.RLock()
+	pgid, err := e.foregroundPgid()
+	e.mu.RUnlock()
+	if err != nil {
+		return Process{}, err
+	}
+	if pgid <= 0 {
+		return Process{}, ErrNoProcess
+	}
+	return readProcess(pgid)
+}
+
+// Pid returns the pid of the process started with StartCommand, or 0 if no
+// process was started or it has exited. Compare it with ForegroundProcess to
+// tell whether the shell itself is in the foreground.
+func (e *Emulator) Pid() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	pid, err := e.runningPid()
+	if err != nil || e.processExited {
+		return 0
+	}
+	return pid
+}
+
+// SetOnForegroundChange sets a callback function that will be called when the
+// foreground process group of the PTY changes, including once for the started
+// process itself. It receives the emulator ID and the new foreground process,
+// and is called from a watcher goroutine while the started process runs.
+func (e *Emulator) SetOnForegroundChange(callback func(id string, p Process)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onForeground = callback
+}
+
+// watchForeground polls the foreground process group until the process
+// started with StartCommand exits, calling the foreground change callback when
+// it changes.
+func (e *Emulator) watchForeground(exited <-chan struct{}) {
+	ticker := time.NewTicker(foregroundPollInterval)
+	defer ticker.Stop()
+
+	last := 0
+	for {
+		select {
+		case <-e.stopChan:
+			return
+		case <-exited:
+			return
+		case <-ticker.C:
+		}
+
+		e.mu.RLock()
+		pgid, err := e.foregroundPgid()
+		onForeground, id := e.onForeground, e.id
+		e.mu.RUnlock()
+		if err != nil || pgid <= 0 || pgid == last {
+			continue
+		}
+		last = pgid
+		if onForeground == nil {
+			continue
+		}
+		if p, err := readProcess(pgid); err == nil {
+			onForeground(id, p)
+		}
+	}
+}
+
+// readProcess reads the description of process pid from /proc.
+func readProcess(pid int) (Process, error) {
+	dir := "/proc/" + strconv.Itoa(pid) + "/"
+	comm, err := os.ReadFile(dir + "comm")
+	if err != nil {
+		return Process{}, err
+	}
+	cmdline, err := os.ReadFile(dir + "cmdline")
+	if err != nil {
+		return Process{}, err
+	}
+	p := Process{Pid: pid, Name: strings.TrimSuffix(string(comm), "\n")}
+	if cmdline = bytes.TrimSuffix(cmdline, []byte{0}); len(cmdline) > 0 {
+		for _, arg := range bytes.Split(cmdline, []byte{0}) {
+			p.Args = append(p.Args, string(arg))
+		}
+	}
+	p.StartTime, err = processStartTime(dir)
+	return p, err
+}
+
+// processStartTime returns when the process with /proc directory dir
+// started, from its start time in clock ticks since boot.
+func processStartTime(dir string) (time.Time, error) {
+	stat, err := os.ReadFile(dir + "stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	// The command name in field 2 may contain spaces and parentheses, so
+	// count fields from the last ')'. Start time is field 22.
+	i := bytes.LastIndexByte(stat, ')')
+	if i < 0 {
+		return time.Time{}, os.ErrInvalid
+	}
+	fields := strings.Fields(string(stat[i+1:]))
+	if len(fields) < 20 {
+		return time.Time{}, os.ErrInvalid
+	}
+	ticks, err := strconv.ParseInt(fields[19], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	boot, err := bootTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return boot.Add(time.Duration(ticks) * time.Second / clockTicks), nil
+}
+
+// bootTime returns when the system booted, from the btime line of /proc/stat.
+func bootTime() (time.Time, error) {
+	stat, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return time.Time{}, err
+	}
+	for line := range strings.Lines(string(stat)) {
+		if rest, ok := strings.CutPrefix(line, "btime "); ok {
+			secs, err := strconv.ParseInt(strings.TrimSpace(rest), 10, 64)
+			if err != nil {
+				return time.Time{}, err
+			}
+			return time.Unix(secs, 0), nil
+		}
+	}
+	return time.Time{}, os.ErrNotExist
+}
@@ -0,0 +1,62 @@
+package emulator
+
+import (
+	"os/exec"
+	"slices"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestForegroundProcess(t *testing.T) {
+	e := startProcess(t, exec.Command("sleep", "10"))
+	defer e.Signal(syscall.SIGKILL)
+
+	p, err := e.ForegroundProcess()
+	if err != nil {
+		t.Fatalf("ForegroundProcess failed: %v", err)
+	}
+	if p.Pid != e.Pid() || p.Name != "sleep" || !slices.Equal(p.Args, []string{"sleep", "10"}) {
+		t.Fatalf("unexpected process %+v (started pid %d)", p, e.Pid())
+	}
+	if d := time.Since(p.StartTime); d < -time.Second || d > time.Minute {
+		t.Fatalf("start time %v is %v ago", p.StartTime, d)
+	}
+
+	if _, err := newDamageEmulator(t).ForegroundProcess(); err == nil {
+		t.Fatal("expected an error without a process")
+	}
+}
+
+func TestOnForegroundChange(t *testing.T) {
+	e, err := New(80, 24)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Close()
+
+	changes := make(chan Process, 4)
+	e.SetOnForegroundChange(func(id string, p Process) {
+		changes <- p
+	})
+	// With job control (-m) the shell runs sleep as a foreground job in a
+	// process group of its own.
+	if err := e.StartCommand(exec.Command("sh", "-mc", "sleep 0.5; sleep 10")); err != nil {
+		t.Fatalf("StartCommand failed: %v", err)
+	}
+	defer e.Signal(syscall.SIGKILL)
+
+	var names []string
+	timeout := time.After(3 * time.Second)
+	for !slices.Contains(names, "sleep 10") {
+		select {
+		case p := <-changes:
+			names = append(names, p.Name+" "+p.Args[len(p.Args)-1])
+		case <-timeout:
+			t.Fatalf("timed out waiting for the second sleep, got %q", names)
+		}
+	}
+	if len(names) < 2 {
+		t.Fatalf("foreground changes %q, want the earlier foreground too", names)
+	}
+}
@@ -0,0 +1,100 @@
+package emulator
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxKittyStack is the depth of each screen's kitty keyboard flags stack,
+// matching kitty.
+const maxKittyStack = 8
+
+// kittyKeyboard is the kitty keyboard protocol state of one screen: the active
+// progressive enhancement flags and the flags saved by CSI > u.
+type kittyKeyboard struct {
+	flags int
+	stack []int
+}
+
+// registerKittyHandlers hooks the vt parser to track the kitty keyboard
+// protocol flags, which vt does not implement. The main and alternate screens
+// keep separate flags, as in kitty.
+func (e *Emulator) registerKittyHandlers() {
+	// CSI = flags ; mode u sets (1), adds (2) or removes (3) flags.
+	e.vt.RegisterCsiHandler(ansi.Command('=', 0, 'u'), func(params ansi.Params) bool {
+		flags, _, _ := params.Param(0, 0)
+		mode, _, _ := params.Param(1, 1)
+		kb := e.kittyKeyboard()
+		switch mode {
+		case 1:
+			kb.flags = flags
+		case 2:
+			kb.flags |= flags
+		case 3:
+			kb.flags &^= flags
+		}
+		kb.flags &= ansi.KittyAllFlags
+		return true
+	})
+
+	// CSI > flags u pushes the active flags and activates flags.
+	e.vt.RegisterCsiHandler(ansi.Command('>', 0, 'u'), func(params ansi.Params) bool {
+		flags, _, _ := params.Param(0, 0)
+		kb := e.kittyKeyboard()
+		if len(kb.stack) == maxKittyStack {
+			kb.stack = kb.stack[1:]
+		}
+		kb.stack = append(kb.stack, kb.flags)
+		kb.flags = flags & ansi.KittyAllFlags
+		return true
+	})
+
+	// CSI < n u pops n entries, restoring the flags saved by the last of
+	// them. Popping more entries than were pushed resets the flags.
+	e.vt.RegisterCsiHandler(ansi.Command('<', 0, 'u'), func(params ansi.Params) bool {
+		n, _, _ := params.Param(0, 1)
+		kb := e.kittyKeyboard()
+		for ; n > 0; n-- {
+			if len(kb.stack) == 0 {
+				kb.flags = 0
+				break
+			}
+			kb.flags = kb.stack[len(kb.stack)-1]
+			kb.stack = kb.stack[:len(kb.stack)-1]
+		}
+		return true
+	})
+
+	// CSI ? u reports the active flags as CSI ? flags u.
+	e.vt.RegisterCsiHandler(ansi.Command('?', 0, 'u'), func(ansi.Params) bool {
+		flags := e.kittyKeyboard().flags
+		io.WriteString(e.vt.InputPipe(), "\x1b[?"+strconv.Itoa(flags)+"u")
+		return true
+	})
+
+	// RIS (ESC c) clears the flags of both screens.
+	e.vt.RegisterEscHandler('c', func() bool {
+		e.kitty = [2]kittyKeyboard{}
+		return false
+	})
+}
+
+// kittyKeyboard returns the kitty keyboard state of the active screen.
+// Must be called with mu held.
+func (e *Emulator) kittyKeyboard() *kittyKeyboard {
+	if e.vt.IsAltScreen() {
+		return &e.kitty[1]
+	}
+	return &e.kitty[0]
+}
+
+// KittyKeyboardFlags returns the kitty keyboard protocol progressive
+// enhancement flags (ansi.KittyDisambiguateEscapeCodes and friends) the child
+// has enabled on the active screen, or 0 if it uses legacy key encoding.
+func (e *Emulator) KittyKeyboardFlags() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.kittyKeyboard().flags
+}
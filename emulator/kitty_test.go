@@ -0,0 +1,77 @@
+package emulator
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestKittyKeyboardFlags(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	steps := []struct {
+		seq  string
+		want int
+	}{
+		{"\x1b[>1u", 1},    // push
+		{"\x1b[>11u", 11},  // push again
+		{"\x1b[=4;2u", 15}, // add
+		{"\x1b[=2;3u", 13}, // remove
+		{"\x1b[=3u", 3},    // set
+		{"\x1b[<u", 1},     // pop back to the first push
+		{"\x1b[>255u", 31}, // unknown bits are dropped
+		{"\x1b[<5u", 0},    // popping past the bottom resets
+		{"\x1b[=1u", 1},    // set without a push
+		{"\x1bc", 0},       // RIS
+	}
+	for _, step := range steps {
+		feed(e, step.seq)
+		if got := e.KittyKeyboardFlags(); got != step.want {
+			t.Fatalf("after %q: flags %d, want %d", step.seq, got, step.want)
+		}
+	}
+}
+
+func TestKittyKeyboardFlagsPerScreen(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "\x1b[>1u\x1b[?1049h")
+	if got := e.KittyKeyboardFlags(); got != 0 {
+		t.Fatalf("alt screen flags %d, want 0", got)
+	}
+	feed(e, "\x1b[>8u")
+	if got := e.KittyKeyboardFlags(); got != 8 {
+		t.Fatalf("alt screen flags %d, want 8", got)
+	}
+	feed(e, "\x1b[?1049l")
+	if got := e.KittyKeyboardFlags(); got != 1 {
+		t.Fatalf("main screen flags %d, want 1", got)
+	}
+}
+
+func TestKittyKeyboardQuery(t *testing.T) {
+	pr, _ := io.Pipe()
+	ir, iw := io.Pipe()
+	e, err := NewFromPipes(10, 4, pr, iw)
+	if err != nil {
+		t.Fatalf("NewFromPipes failed: %v", err)
+	}
+	defer e.Close()
+
+	reply := make(chan string, 1)
+	go func() {
+		buf := make([]byte, 32)
+		n, _ := ir.Read(buf)
+		reply <- string(buf[:n])
+	}()
+
+	feed(e, "\x1b[>5u\x1b[?u")
+	select {
+	case got := <-reply:
+		if got != "\x1b[?5u" {
+			t.Fatalf("query reply %q, want %q", got, "\x1b[?5u")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the query reply")
+	}
+}
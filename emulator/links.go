@@ -0,0 +1,174 @@
+package emulator
+
+import (
+	"bytes"
+	"strings"
+
+	uv "github.com/charmbracelet/ultraviolet"
+)
+
+// Hyperlink is a run of adjacent cells on one screen row that the child
+// linked to the same target with OSC 8. A link that wraps onto several rows
+// is reported as one Hyperlink per row.
+type Hyperlink struct {
+	URL    string
+	Params string // OSC 8 parameters, such as "id=1"
+	Row    int
+	Col    int    // First column of the run
+	Width  int    // Number of columns the run covers
+	Text   string // Text shown for the link
+}
+
+// registerLinkHandlers hooks the vt parser to handle OSC 8 hyperlinks. The vt
+// handler stores the params as the URL and the URI as the params, and drops
+// URIs containing ';', so links are tracked here instead and set on the cells
+// the child prints while one is open.
+func (e *Emulator) registerLinkHandlers() {
+	// OSC 8 ; params ; URI starts a hyperlink, or ends it when URI is empty.
+	// The URI may itself contain ';'.
+	e.vt.RegisterOscHandler(8, func(data []byte) bool {
+		_, rest, _ := bytes.Cut(data, []byte{';'})
+		params, uri, ok := bytes.Cut(rest, []byte{';'})
+		if !ok {
+			return true
+		}
+		if !e.link.IsZero() && e.linkCol == e.width-1 {
+			// A character printed to the last column leaves the cursor
+			// there.
+			e.linkChanged()
+		}
+		e.link = uv.Link{}
+		if len(uri) > 0 {
+			e.link = uv.Link{URL: string(uri), Params: string(params)}
+			e.markLinkCell(e.vt.CursorPosition())
+		}
+		return true
+	})
+
+	// RIS (ESC c) ends the hyperlink.
+	e.vt.RegisterEscHandler('c', func() bool {
+		e.link = uv.Link{}
+		return false
+	})
+}
+
+// followLink is the vt CursorPosition callback. While a hyperlink is open, it
+// sets the link on the cell the cursor leaves if the child printed to it, and
+// records the cell the cursor moves to. vt moves the cursor past each
+// character it prints, except on the last column: there the cursor stays, and
+// the next character wraps to the start of the following row. Must be called
+// with mu held.
+func (e *Emulator) followLink(old, pos uv.Position) {
+	if e.link.IsZero() {
+		return
+	}
+	edge := e.linkCol == e.width-1
+	right := pos.Y == old.Y && pos.X > old.X
+	switch {
+	case edge || right && e.historyLine(old.Y) == e.linkLine && old.X == e.linkCol:
+		e.linkChanged()
+	case right:
+		// The screen scrolled under the cursor since it moved, so the
+		// recorded cell is elsewhere; take a character as wide as the
+		// move as printed.
+		if c := e.vt.CellAt(old.X, old.Y); c != nil && c.Width == pos.X-old.X && c.Content != " " {
+			e.setLink(old.X, old.Y)
+		}
+	}
+	if edge && pos.Y == old.Y && pos.X < old.X {
+		// A character that wrapped lands at the start of the row.
+		if c := e.vt.CellAt(0, pos.Y); c != nil && c.Width == pos.X && c.Content != "" {
+			e.setLink(0, pos.Y)
+		}
+	}
+	e.markLinkCell(pos)
+}
+
+// markLinkCell records the cell at pos, so linkChanged can tell whether the
+// child prints to it. Must be called with mu held.
+func (e *Emulator) markLinkCell(pos uv.Position) {
+	e.linkLine, e.linkCol = e.historyLine(pos.Y), pos.X
+	e.linkCell = uv.Cell{}
+	if c := e.vt.CellAt(pos.X, pos.Y); c != nil {
+		e.linkCell = *c
+	}
+}
+
+// linkChanged sets the open hyperlink on the cell recorded by markLinkCell if
+// it changed since, following it as the screen scrolls. Must be called with
+// mu held.
+func (e *Emulator) linkChanged() {
+	y := e.linkLine - e.historyLine(0)
+	if c := e.vt.CellAt(e.linkCol, y); c != nil && !c.Equal(&e.linkCell) {
+		e.setLink(e.linkCol, y)
+	}
+}
+
+// setLink sets the open hyperlink on the cell at (x, y). Must be called with
+// mu held.
+func (e *Emulator) setLink(x, y int) {
+	// Setting a wide character resets its trailing cells, so those are
+	// left alone.
+	if c := e.vt.CellAt(x, y); c != nil && c.Width > 0 && c.Link != e.link {
+		linked := *c
+		linked.Link = e.link
+		e.vt.SetCell(x, y, &linked)
+	}
+}
+
+// Links returns the hyperlinks on the screen, from top to bottom and left to
+// right.
+func (e *Emulator) Links() []Hyperlink {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	var links []Hyperlink
+	for y := range e.height {
+		links = append(links, lineLinks(e.lineCells(y), y)...)
+	}
+	return links
+}
+
+// LinkAt returns the hyperlink covering the screen cell at (x, y), and
+// whether there is one.
+func (e *Emulator) LinkAt(x, y int) (Hyperlink, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if y < 0 || y >= e.height {
+		return Hyperlink{}, false
+	}
+	for _, link := range lineLinks(e.lineCells(y), y) {
+		if x >= link.Col && x < link.Col+link.Width {
+			return link, true
+		}
+	}
+	return Hyperlink{}, false
+}
+
+// lineLinks returns the hyperlinks in the cells of screen row y.
+func lineLinks(cells []uv.Cell, y int) []Hyperlink {
+	var links []Hyperlink
+	var text strings.Builder
+	for x := 0; x < len(cells); {
+		link := cells[x].Link
+		if link.IsZero() {
+			x++
+			continue
+		}
+		start := x
+		text.Reset()
+		// The trailing cells of a wide character are empty and belong to
+		// the run too.
+		for ; x < len(cells) && (cells[x].Link == link || cells[x].Width == 0); x++ {
+			text.WriteString(cells[x].Content)
+		}
+		links = append(links, Hyperlink{
+			URL:    link.URL,
+			Params: link.Params,
+			Row:    y,
+			Col:    start,
+			Width:  x - start,
+			Text:   text.String(),
+		})
+	}
+	return links
+}
@@ -0,0 +1,101 @@
+package emulator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	// The first link fills the rest of the first row.
+	feed(e, "ls \x1b]8;id=7;file:///tmp/a.go\x1b\\main.go\x1b]8;;\x1b\\ \x1b]8;;https://x.dev\x07x\x1b]8;;\x07")
+	want := []Hyperlink{
+		{URL: "file:///tmp/a.go", Params: "id=7", Row: 0, Col: 3, Width: 7, Text: "main.go"},
+		{URL: "https://x.dev", Row: 1, Col: 1, Width: 1, Text: "x"},
+	}
+	if got := e.Links(); !slices.Equal(got, want) {
+		t.Fatalf("links %+v, want %+v", got, want)
+	}
+
+	if link, ok := e.LinkAt(9, 0); !ok || link != want[0] {
+		t.Fatalf("LinkAt(9, 0) = %+v, %v", link, ok)
+	}
+	for _, pos := range [][2]int{{2, 0}, {0, 1}, {1, 5}} {
+		if link, ok := e.LinkAt(pos[0], pos[1]); ok {
+			t.Fatalf("LinkAt%v = %+v, want none", pos, link)
+		}
+	}
+
+	// Rendered rows keep the link with its URL and params in order.
+	if row := e.GetScreen().Rows[0]; !strings.Contains(row, "\x1b]8;id=7;file:///tmp/a.go") {
+		t.Fatalf("row %q does not carry the link", row)
+	}
+}
+
+func TestLinkURIWithSemicolon(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	// The link is set on text as it is written, across writes.
+	feed(e, "\x1b]8;id=1;https://x.dev/a;b=1\x07ab")
+	want := Hyperlink{URL: "https://x.dev/a;b=1", Params: "id=1", Row: 0, Col: 0, Width: 2, Text: "ab"}
+	if link, ok := e.LinkAt(1, 0); !ok || link != want {
+		t.Fatalf("LinkAt(1, 0) = %+v, %v, want %+v", link, ok, want)
+	}
+	feed(e, "c\x1b]8;;\x07d")
+	want.Width, want.Text = 3, "abc"
+	if got := e.Links(); !slices.Equal(got, []Hyperlink{want}) {
+		t.Fatalf("links %+v, want %+v", got, want)
+	}
+}
+
+func TestLinkWrapsAndScrolls(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	// The link starts on the last row and wraps, scrolling the screen up.
+	feed(e, "\n\n\n\x1b]8;;https://x.dev\x070123456789ab\x1b]8;;\x07")
+	want := []Hyperlink{
+		{URL: "https://x.dev", Row: 2, Col: 0, Width: 10, Text: "0123456789"},
+		{URL: "https://x.dev", Row: 3, Col: 0, Width: 2, Text: "ab"},
+	}
+	if got := e.Links(); !slices.Equal(got, want) {
+		t.Fatalf("links %+v, want %+v", got, want)
+	}
+}
+
+func TestLinkFollowsPrintedCells(t *testing.T) {
+	link := func(row, col int, text string) Hyperlink {
+		return Hyperlink{URL: "https://x.dev", Row: row, Col: col, Width: len(text), Text: text}
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  []Hyperlink
+	}{
+		{"cursor position", "aaaa\r\nbbbb\r\ncccc\x1b]8;;https://x.dev\x07L\x1b[3;10HM\x1b]8;;\x07",
+			[]Hyperlink{link(2, 4, "L"), link(2, 9, "M")}},
+		{"new line", "\x1b]8;;https://x.dev\x07ab\r\ncd\x1b]8;;\x07",
+			[]Hyperlink{link(0, 0, "ab"), link(1, 0, "cd")}},
+		{"new line at the bottom", "\n\n\n\x1b]8;;https://x.dev\x07ab\r\ncd\x1b]8;;\x07",
+			[]Hyperlink{link(2, 0, "ab"), link(3, 0, "cd")}},
+		{"cursor up", "\n\n\x1b]8;;https://x.dev\x07x\x1b[2Ay\x1b]8;;\x07",
+			[]Hyperlink{link(0, 1, "y"), link(2, 0, "x")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Links do not depend on how the output is split into writes.
+			whole := newDamageEmulator(t)
+			feed(whole, tt.input)
+			split := newDamageEmulator(t)
+			for i := range len(tt.input) {
+				feed(split, tt.input[i:i+1])
+			}
+			for _, e := range []*Emulator{whole, split} {
+				if got := e.Links(); !slices.Equal(got, tt.want) {
+					t.Fatalf("links %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
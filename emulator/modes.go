@@ -0,0 +1,83 @@
+package emulator
+
+import "github.com/charmbracelet/x/ansi"
+
+// defaultModes returns the modes the vt emulator starts with set. Modes
+// missing from the map are reset.
+func defaultModes() ansi.Modes {
+	return ansi.Modes{
+		ansi.ModeAutoWrap:         ansi.ModeSet,
+		ansi.ModeTextCursorEnable: ansi.ModeSet,
+	}
+}
+
+// registerModeHandlers hooks the vt parser to keep the mode mirror in step
+// with vt.
+func (e *Emulator) registerModeHandlers() {
+	// RIS (ESC c) resets every mode, but vt only fires callbacks for some of
+	// them, so mouse encodings, for one, would otherwise stay set.
+	e.vt.RegisterEscHandler('c', func() bool {
+		e.modes = defaultModes()
+		return false
+	})
+}
+
+// isModeSet reports whether the child has enabled mode.
+// Must be called with mu held.
+func (e *Emulator) isModeSet(mode ansi.Mode) bool {
+	return e.modes[mode].IsSet()
+}
+
+// IsMouseTracking reports whether the child has enabled any mouse tracking
+// mode (X10, normal, highlight, button-event or any-event). When it has not,
+// mouse events sent to the emulator are dropped, so callers are free to use
+// the mouse for their own purposes such as scrolling.
+func (e *Emulator) IsMouseTracking() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.mouseMode() != MouseModeNone
+}
+
+// IsBracketedPaste reports whether the child has enabled bracketed paste
+// (mode 2004), in which case Paste wraps pasted text in ESC[200~ / ESC[201~.
+func (e *Emulator) IsBracketedPaste() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isModeSet(ansi.ModeBracketedPaste)
+}
+
+// IsFocusReporting reports whether the child has enabled focus events (mode
+// 1004), in which case SendFocus writes CSI I / CSI O.
+func (e *Emulator) IsFocusReporting() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isModeSet(ansi.ModeFocusEvent)
+}
+
+// IsApplicationCursorKeys reports whether the child has enabled application
+// cursor keys (DECCKM), under which unmodified arrow, Home and End keys send
+// SS3 sequences (ESC O A) instead of CSI sequences (ESC [ A).
+func (e *Emulator) IsApplicationCursorKeys() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isModeSet(ansi.ModeCursorKeys)
+}
+
+// IsApplicationKeypad reports whether the child has enabled the application
+// keypad (DECKPAM, ESC =), under which keypad keys send SS3 sequences instead
+// of the characters on their caps.
+func (e *Emulator) IsApplicationKeypad() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isModeSet(ansi.ModeNumericKeypad)
+}
+
+// IsAltScreen reports whether the alternate screen is shown (modes 1047 and
+// 1049), as full-screen programs such as editors and pagers do. The alternate
+// screen has no scrollback. GetScreen reports switching between it
+// and the main screen as damage with reason CRScreenSwitch.
+func (e *Emulator) IsAltScreen() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.vt.IsAltScreen()
+}
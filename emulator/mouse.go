@@ -0,0 +1,160 @@
+package emulator
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/vt"
+)
+
+// Cell size in pixels reported to the child through the PTY window size and
+// used for SGR-pixels mouse coordinates.
+const (
+	cellPixelWidth  = 8
+	cellPixelHeight = 16
+)
+
+// MouseMode is the mouse tracking mode the child has enabled, which decides
+// which mouse events are reported to it.
+type MouseMode int
+
+const (
+	MouseModeNone        MouseMode = iota // No reporting
+	MouseModeX10                          // Presses only (DECSET 9)
+	MouseModeNormal                       // Presses and releases (DECSET 1000/1001)
+	MouseModeButtonEvent                  // Plus motion while a button is held (DECSET 1002)
+	MouseModeAnyEvent                     // Plus all motion (DECSET 1003)
+)
+
+// MouseEncoding is how mouse reports are encoded.
+type MouseEncoding int
+
+const (
+	MouseEncodingX10       MouseEncoding = iota // CSI M Cb Cx Cy, coordinates up to 223
+	MouseEncodingUTF8                           // CSI M with UTF-8 coordinates (DECSET 1005)
+	MouseEncodingSGR                            // CSI < Cb ; Cx ; Cy M/m (DECSET 1006)
+	MouseEncodingURXVT                          // CSI Cb ; Cx ; Cy M (DECSET 1015)
+	MouseEncodingSGRPixels                      // SGR with pixel coordinates (DECSET 1016)
+)
+
+// mouseMode returns the active mouse tracking mode. When the child enabled
+// several, the one reporting the most events wins. Must be called with mu held.
+func (e *Emulator) mouseMode() MouseMode {
+	switch {
+	case e.isModeSet(ansi.ModeMouseAnyEvent):
+		return MouseModeAnyEvent
+	case e.isModeSet(ansi.ModeMouseButtonEvent):
+		return MouseModeButtonEvent
+	case e.isModeSet(ansi.ModeMouseNormal), e.isModeSet(ansi.ModeMouseHighlight):
+		return MouseModeNormal
+	case e.isModeSet(ansi.ModeMouseX10):
+		return MouseModeX10
+	}
+	return MouseModeNone
+}
+
+// mouseEncoding returns the active mouse encoding, preferring SGR over the
+// older extensions as xterm does. Must be called with mu held.
+func (e *Emulator) mouseEncoding() MouseEncoding {
+	switch {
+	case e.isModeSet(ansi.ModeMouseExtSgrPixel):
+		return MouseEncodingSGRPixels
+	case e.isModeSet(ansi.ModeMouseExtSgr):
+		return MouseEncodingSGR
+	case e.isModeSet(ansi.ModeMouseExtUrxvt):
+		return MouseEncodingURXVT
+	case e.isModeSet(ansi.ModeMouseExtUtf8):
+		return MouseEncodingUTF8
+	}
+	return MouseEncodingX10
+}
+
+// MouseMode returns the mouse tracking mode and encoding the child has
+// enabled. With MouseModeNone, mouse events sent to the emulator are dropped
+// and callers are free to use the mouse themselves, e.g. for selection.
+func (e *Emulator) MouseMode() (MouseMode, MouseEncoding) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.mouseMode(), e.mouseEncoding()
+}
+
+// sendMouse reports ev to the child if its mouse tracking mode asks for it.
+// Must be called with mu held.
+func (e *Emulator) sendMouse(ev vt.Mouse) {
+	mode := e.mouseMode()
+	mouse := ev.Mouse()
+	isWheel := mouse.Button >= vt.MouseWheelUp && mouse.Button <= vt.MouseWheelRight
+
+	var motion, release bool
+	switch ev.(type) {
+	case vt.MouseClick:
+		if !isWheel {
+			e.mouseHeld = mouse.Button
+		}
+	case vt.MouseRelease:
+		e.mouseHeld = vt.MouseNone
+		release = true
+	case vt.MouseMotion:
+		if mouse.Button == vt.MouseNone {
+			mouse.Button = e.mouseHeld
+		}
+		motion = true
+	}
+
+	switch mode {
+	case MouseModeNone:
+		return
+	case MouseModeX10:
+		if release || motion {
+			return
+		}
+		mouse.Mod = 0 // X10 reports carry no modifiers
+	case MouseModeNormal:
+		if motion {
+			return
+		}
+	case MouseModeButtonEvent:
+		if motion && mouse.Button == vt.MouseNone {
+			return
+		}
+	}
+
+	enc := e.mouseEncoding()
+	button := mouse.Button
+	if release && enc != MouseEncodingSGR && enc != MouseEncodingSGRPixels {
+		// Only SGR reports which button was released.
+		button = vt.MouseNone
+	}
+	b := ansi.EncodeMouseButton(button, motion,
+		mouse.Mod.Contains(vt.ModShift),
+		mouse.Mod.Contains(vt.ModAlt),
+		mouse.Mod.Contains(vt.ModCtrl))
+
+	if seq := encodeMouse(enc, b, mouse.X, mouse.Y, release); seq != "" {
+		io.WriteString(e.vt.InputPipe(), seq)
+	}
+}
+
+// encodeMouse formats a mouse report for button code b at cell (x, y). It
+// returns "" when the position cannot be encoded.
+func encodeMouse(enc MouseEncoding, b byte, x, y int, release bool) string {
+	switch enc {
+	case MouseEncodingSGR:
+		return ansi.MouseSgr(b, x, y, release)
+	case MouseEncodingSGRPixels:
+		return ansi.MouseSgr(b, x*cellPixelWidth, y*cellPixelHeight, release)
+	case MouseEncodingURXVT:
+		return "\x1b[" + strconv.Itoa(int(b)+32) + ";" + strconv.Itoa(x+1) + ";" + strconv.Itoa(y+1) + "M"
+	case MouseEncodingUTF8:
+		// Values are offset by 32 and sent as UTF-8, up to xterm's 2047.
+		if x+33 > 2047 || y+33 > 2047 {
+			return ""
+		}
+		return "\x1b[M" + string(rune(int(b)+32)) + string(rune(x+33)) + string(rune(y+33))
+	}
+	if x+33 > 255 || y+33 > 255 {
+		return ""
+	}
+	return ansi.MouseX10(b, x, y)
+}
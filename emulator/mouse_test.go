@@ -0,0 +1,135 @@
+package emulator
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/vt"
+)
+
+// newInputEmulator returns a pipe-backed emulator and a channel carrying what
+// it sends to the child.
+func newInputEmulator(t *testing.T) (*Emulator, <-chan string) {
+	t.Helper()
+	pr, _ := io.Pipe()
+	ir, iw := io.Pipe()
+	e, err := NewFromPipes(80, 24, pr, iw)
+	if err != nil {
+		t.Fatalf("NewFromPipes failed: %v", err)
+	}
+	t.Cleanup(func() { e.Close() })
+
+	input := make(chan string, 16)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := ir.Read(buf)
+			if err != nil {
+				return
+			}
+			input <- string(buf[:n])
+		}
+	}()
+	return e, input
+}
+
+// collectInput returns everything sent to the child until it goes quiet.
+func collectInput(input <-chan string) []string {
+	var got []string
+	for {
+		select {
+		case s := <-input:
+			got = append(got, s)
+		case <-time.After(50 * time.Millisecond):
+			return got
+		}
+	}
+}
+
+// sendMouseSequence clicks the left button at (1,2), drags to (3,2) and
+// releases it there, then moves to (5,6) with no button held and scrolls up.
+func sendMouseSequence(e *Emulator) {
+	e.SendMouse(0, 1, 2, true)
+	e.SendMouse(-1, 3, 2, false)
+	e.SendMouse(0, 3, 2, false)
+	e.SendMouse(-1, 5, 6, false)
+	e.SendMouseWheel(int(vt.MouseWheelUp), 5, 6)
+}
+
+func TestMouseModes(t *testing.T) {
+	tests := []struct {
+		name  string
+		modes string
+		mode  MouseMode
+		enc   MouseEncoding
+		want  []string
+	}{
+		{"none", "", MouseModeNone, MouseEncodingX10, nil},
+		{"x10", "\x1b[?9h", MouseModeX10, MouseEncodingX10,
+			[]string{"\x1b[M \"#", "\x1b[M`&'"}},
+		{"normal", "\x1b[?1000h", MouseModeNormal, MouseEncodingX10,
+			[]string{"\x1b[M \"#", "\x1b[M#$#", "\x1b[M`&'"}},
+		{"button event", "\x1b[?1002h", MouseModeButtonEvent, MouseEncodingX10,
+			[]string{"\x1b[M \"#", "\x1b[M@$#", "\x1b[M#$#", "\x1b[M`&'"}},
+		{"any event", "\x1b[?1003h", MouseModeAnyEvent, MouseEncodingX10,
+			[]string{"\x1b[M \"#", "\x1b[M@$#", "\x1b[M#$#", "\x1b[MC&'", "\x1b[M`&'"}},
+		{"sgr", "\x1b[?1002h\x1b[?1006h", MouseModeButtonEvent, MouseEncodingSGR,
+			[]string{"\x1b[<0;2;3M", "\x1b[<32;4;3M", "\x1b[<0;4;3m", "\x1b[<64;6;7M"}},
+		{"sgr pixels", "\x1b[?1000h\x1b[?1016h", MouseModeNormal, MouseEncodingSGRPixels,
+			[]string{"\x1b[<0;9;33M", "\x1b[<0;25;33m", "\x1b[<64;41;97M"}},
+		{"urxvt", "\x1b[?1000h\x1b[?1015h", MouseModeNormal, MouseEncodingURXVT,
+			[]string{"\x1b[32;2;3M", "\x1b[35;4;3M", "\x1b[96;6;7M"}},
+		{"utf8", "\x1b[?1000h\x1b[?1005h", MouseModeNormal, MouseEncodingUTF8,
+			[]string{"\x1b[M \"#", "\x1b[M#$#", "\x1b[M`&'"}},
+		{"sgr wins over urxvt", "\x1b[?1000h\x1b[?1015h\x1b[?1006h", MouseModeNormal, MouseEncodingSGR,
+			[]string{"\x1b[<0;2;3M", "\x1b[<0;4;3m", "\x1b[<64;6;7M"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, input := newInputEmulator(t)
+			feed(e, tt.modes)
+
+			mode, enc := e.MouseMode()
+			if mode != tt.mode || enc != tt.enc {
+				t.Fatalf("MouseMode() = %v, %v; want %v, %v", mode, enc, tt.mode, tt.enc)
+			}
+			if e.IsMouseTracking() != (tt.mode != MouseModeNone) {
+				t.Fatalf("IsMouseTracking() = %v with mode %v", e.IsMouseTracking(), mode)
+			}
+
+			sendMouseSequence(e)
+			got := collectInput(input)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("report %d: got %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestResetClearsMouseModes(t *testing.T) {
+	for _, enc := range []string{"1005", "1006", "1015", "1016"} {
+		e, _ := newInputEmulator(t)
+		feed(e, "\x1b[?1003h\x1b[?"+enc+"h\x1bc")
+		if mode, got := e.MouseMode(); mode != MouseModeNone || got != MouseEncodingX10 {
+			t.Fatalf("mode %s after RIS: MouseMode() = %v, %v", enc, mode, got)
+		}
+	}
+}
+
+func TestMouseEncodingLimits(t *testing.T) {
+	if got := encodeMouse(MouseEncodingX10, 0, 222, 0, false); got == "" {
+		t.Fatal("x=222 should fit the X10 encoding")
+	}
+	if got := encodeMouse(MouseEncodingX10, 0, 223, 0, false); got != "" {
+		t.Fatalf("x=223 should not fit the X10 encoding, got %q", got)
+	}
+	if got := encodeMouse(MouseEncodingUTF8, 0, 300, 0, false); got != "\x1b[M ō!" {
+		t.Fatalf("UTF-8 encoding of x=300 = %q", got)
+	}
+}
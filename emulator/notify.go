@@ -0,0 +1,98 @@
+package emulator
+
+import "bytes"
+
+// Notification is a desktop notification the child requested with OSC 9 or
+// OSC 777.
+type Notification struct {
+	Title string // Empty for OSC 9, which only carries a body
+	Body  string
+}
+
+// registerNotifyHandlers hooks the vt parser to handle desktop notification
+// requests, which vt ignores.
+func (e *Emulator) registerNotifyHandlers() {
+	// OSC 9 ; body is the iTerm2 notification. ConEmu uses OSC 9 ; Ps ; ...
+	// with a numeric Ps for other commands, which are not notifications.
+	e.vt.RegisterOscHandler(9, func(data []byte) bool {
+		_, body, ok := bytes.Cut(data, []byte{';'})
+		if !ok || isConEmuCommand(body) {
+			return true
+		}
+		e.notify(Notification{Body: string(body)})
+		return true
+	})
+
+	// OSC 777 ; notify ; title ; body is the urxvt notification.
+	e.vt.RegisterOscHandler(777, func(data []byte) bool {
+		parts := bytes.SplitN(data, []byte{';'}, 4)
+		if len(parts) < 3 || string(parts[1]) != "notify" {
+			return true
+		}
+		n := Notification{Title: string(parts[2])}
+		if len(parts) == 4 {
+			n.Body = string(parts[3])
+		}
+		e.notify(n)
+		return true
+	})
+}
+
+// isConEmuCommand reports whether an OSC 9 payload is a ConEmu command: a
+// number followed by ';' or nothing else.
+func isConEmuCommand(payload []byte) bool {
+	n, _, _ := bytes.Cut(payload, []byte{';'})
+	if len(n) == 0 {
+		return false
+	}
+	for _, c := range n {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ringBell counts a BEL and queues the bell callback. Must be called with mu
+// held.
+func (e *Emulator) ringBell() {
+	e.bells++
+	if onBell := e.onBell; onBell != nil {
+		id := e.id
+		e.queueEvent(func() { onBell(id) })
+	}
+}
+
+// notify queues the notification callback. Must be called with mu held.
+func (e *Emulator) notify(n Notification) {
+	if onNotify := e.onNotify; onNotify != nil {
+		id := e.id
+		e.queueEvent(func() { onNotify(id, n) })
+	}
+}
+
+// BellCount returns the number of times the child rang the bell (BEL).
+func (e *Emulator) BellCount() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.bells
+}
+
+// SetOnBell sets a callback function that will be called when the child rings
+// the bell. It receives the emulator ID and is called from the read loop
+// goroutine.
+func (e *Emulator) SetOnBell(callback func(id string)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onBell = callback
+}
+
+// SetOnNotification sets a callback function that will be called when the
+// child requests a desktop notification with OSC 9 or OSC 777. It receives the
+// emulator ID and the notification, and is called from the read loop
+// goroutine.
+func (e *Emulator) SetOnNotification(callback func(id string, n Notification)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onNotify = callback
+}
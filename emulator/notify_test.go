@@ -0,0 +1,55 @@
+package emulator
+
+import "testing"
+
+func TestBell(t *testing.T) {
+	e := newDamageEmulator(t)
+	rings := 0
+	e.SetOnBell(func(id string) {
+		if id != e.ID() {
+			t.Errorf("callback got ID %q, want %q", id, e.ID())
+		}
+		rings++
+	})
+
+	feed(e, "a\x07b\x07\x07")
+	if e.BellCount() != 3 || rings != 3 {
+		t.Fatalf("bell count %d, callbacks %d, want 3", e.BellCount(), rings)
+	}
+
+	// A BEL terminating an OSC is not a bell.
+	feed(e, "\x1b]0;title\x07")
+	if e.BellCount() != 3 {
+		t.Fatalf("bell count %d after OSC, want 3", e.BellCount())
+	}
+}
+
+func TestNotification(t *testing.T) {
+	e := newDamageEmulator(t)
+	var got []Notification
+	e.SetOnNotification(func(id string, n Notification) {
+		if id != e.ID() {
+			t.Errorf("callback got ID %q, want %q", id, e.ID())
+		}
+		got = append(got, n)
+	})
+
+	feed(e, "\x1b]9;build done\x07")
+	feed(e, "\x1b]777;notify;make;exit 0; 3s\x1b\\")
+	feed(e, "\x1b]777;notify;title only\x07")
+	feed(e, "\x1b]9;4;1;50\x07")    // ConEmu progress
+	feed(e, "\x1b]777;other;x\x07") // not a notification
+	want := []Notification{
+		{Body: "build done"},
+		{Title: "make", Body: "exit 0; 3s"},
+		{Title: "title only"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d notifications %+v, want %+v", len(got), got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("notification %d is %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
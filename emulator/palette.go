@@ -0,0 +1,283 @@
+package emulator
+
+import (
+	"bytes"
+	"image/color"
+	"io"
+	"strconv"
+
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// registerPaletteHandlers hooks the vt parser to set, reset and answer
+// queries for the color palette (OSC 4 and 104) and the default foreground,
+// background and cursor colors (OSC 10, 11 and 12, 110 and 111). OSC 112 and
+// a bare OSC 12 are handled with the cursor.
+func (e *Emulator) registerPaletteHandlers() {
+	// OSC 4 ; c ; spec [; c ; spec ...] sets or, for a spec of "?", queries
+	// palette entry c.
+	e.vt.RegisterOscHandler(4, func(data []byte) bool {
+		parts := bytes.Split(data, []byte{';'})[1:]
+		for i := 0; i+1 < len(parts); i += 2 {
+			c, err := strconv.Atoi(string(parts[i]))
+			if err != nil || c < 0 || c > 255 {
+				continue
+			}
+			if string(parts[i+1]) == "?" {
+				e.replyColor("4;"+strconv.Itoa(c), e.paletteColor(c))
+			} else if col := ansi.XParseColor(string(parts[i+1])); col != nil {
+				e.childPalette[c] = col
+				e.themeChanged()
+			}
+		}
+		return true
+	})
+
+	// OSC 104 [; c ...] resets the listed palette entries, or all of them.
+	e.vt.RegisterOscHandler(104, func(data []byte) bool {
+		parts := bytes.Split(data, []byte{';'})[1:]
+		if len(parts) == 0 || (len(parts) == 1 && len(parts[0]) == 0) {
+			e.childPalette = [256]color.Color{}
+		}
+		for _, part := range parts {
+			if c, err := strconv.Atoi(string(part)); err == nil && c >= 0 && c <= 255 {
+				e.childPalette[c] = nil
+			}
+		}
+		e.themeChanged()
+		return true
+	})
+
+	// OSC Ps ; spec [; spec ...] sets or queries dynamic color Ps, and each
+	// further spec applies to the next one, so OSC 10;?;? queries both the
+	// foreground and the background.
+	for _, cmd := range []int{10, 11, 12} {
+		e.vt.RegisterOscHandler(cmd, func(data []byte) bool {
+			parts := bytes.Split(data, []byte{';'})[1:]
+			if len(parts) == 0 {
+				if cmd == 12 {
+					return false
+				}
+				e.setDynamicColor(cmd, nil)
+				return true
+			}
+			for i, spec := range parts {
+				if cmd+i > 12 {
+					break
+				}
+				if string(spec) == "?" {
+					e.replyColor(strconv.Itoa(cmd+i), e.dynamicColor(cmd+i))
+				} else if c := ansi.XParseColor(string(spec)); c != nil {
+					e.setDynamicColor(cmd+i, c)
+				}
+			}
+			return true
+		})
+	}
+	for _, cmd := range []int{110, 111} {
+		e.vt.RegisterOscHandler(cmd, func([]byte) bool {
+			e.setDynamicColor(cmd-100, nil)
+			return true
+		})
+	}
+
+	// RIS (ESC c) drops the colors set by the child.
+	e.vt.RegisterEscHandler('c', func() bool {
+		e.childPalette = [256]color.Color{}
+		e.childFg, e.childBg = nil, nil
+		e.themeChanged()
+		return false
+	})
+}
+
+// replyColor answers a color query for OSC cmd, which includes the palette
+// index for OSC 4.
+func (e *Emulator) replyColor(cmd string, c color.Color) {
+	_, _ = io.WriteString(e.vt.InputPipe(), "\x1b]"+cmd+";"+ansi.XRGBColor{Color: c}.String()+"\x07")
+}
+
+// setDynamicColor sets dynamic color cmd (OSC 10, 11 or 12) for the child, or
+// resets it when c is nil. Must be called with mu held.
+func (e *Emulator) setDynamicColor(cmd int, c color.Color) {
+	switch cmd {
+	case 10:
+		e.childFg = c
+	case 11:
+		e.childBg = c
+	case 12:
+		if c == nil {
+			e.resetCursorColor()
+		} else {
+			e.vt.SetCursorColor(c)
+		}
+		return
+	}
+	e.themeChanged()
+}
+
+// dynamicColor returns the color reported for a query of OSC 10, 11 or 12.
+// Colors left to the host terminal are reported as white on black. Must be
+// called with mu held.
+func (e *Emulator) dynamicColor(cmd int) color.Color {
+	var c, fallback color.Color
+	switch cmd {
+	case 10:
+		c, fallback = e.foregroundColor(), color.White
+	case 11:
+		c, fallback = e.backgroundColor(), color.Black
+	case 12:
+		c, fallback = e.cursorState().Color, color.White
+	}
+	if c == nil {
+		return fallback
+	}
+	return c
+}
+
+// paletteColor returns palette entry i: as set by the child, configured with
+// SetPalette or, failing both, the standard xterm color. Must be called with
+// mu held.
+func (e *Emulator) paletteColor(i int) color.Color {
+	if c := e.themeColor(i); c != nil {
+		return c
+	}
+	return ansi.IndexedColor(i)
+}
+
+// themeColor returns palette entry i as set by the child or configured with
+// SetPalette, or nil when it is left to the host terminal. Must be called
+// with mu held.
+func (e *Emulator) themeColor(i int) color.Color {
+	if c := e.childPalette[i]; c != nil {
+		return c
+	}
+	return e.palette[i]
+}
+
+// foregroundColor returns the default foreground color, or nil when it is
+// left to the host terminal. Must be called with mu held.
+func (e *Emulator) foregroundColor() color.Color {
+	if e.childFg != nil {
+		return e.childFg
+	}
+	return e.defaultFg
+}
+
+// backgroundColor returns the default background color, or nil when it is
+// left to the host terminal. Must be called with mu held.
+func (e *Emulator) backgroundColor() color.Color {
+	if e.childBg != nil {
+		return e.childBg
+	}
+	return e.defaultBg
+}
+
+// themeChanged redraws the screen in the current colors. Must be called with
+// mu held.
+func (e *Emulator) themeChanged() {
+	e.themed = e.foregroundColor() != nil || e.backgroundColor() != nil
+	for i := range e.palette {
+		if e.themed {
+			break
+		}
+		e.themed = e.themeColor(i) != nil
+	}
+	e.damageAll(CRRedraw)
+}
+
+// themeCells returns line with its palette colors and default colors replaced
+// by the colors set by the child or configured on the emulator, so rendered
+// rows look the same on any host terminal. line is returned as is when no
+// color is configured; otherwise it is copied. Must be called with mu held.
+func (e *Emulator) themeCells(line []uv.Cell) []uv.Cell {
+	if !e.themed {
+		return line
+	}
+	fg, bg := e.foregroundColor(), e.backgroundColor()
+	themed := make([]uv.Cell, len(line))
+	for x, c := range line {
+		if !c.IsZero() {
+			c.Style.Fg = e.resolveColor(c.Style.Fg, fg)
+			c.Style.Bg = e.resolveColor(c.Style.Bg, bg)
+			c.Style.UnderlineColor = e.resolveColor(c.Style.UnderlineColor, nil)
+		}
+		themed[x] = c
+	}
+	return themed
+}
+
+// resolveColor maps a cell color to the configured palette, replacing the
+// default color (nil) with def. Must be called with mu held.
+func (e *Emulator) resolveColor(c, def color.Color) color.Color {
+	var i int
+	switch c := c.(type) {
+	case nil:
+		return def
+	case ansi.BasicColor:
+		i = int(c)
+	case ansi.IndexedColor:
+		i = int(c)
+	default:
+		return c
+	}
+	if themed := e.themeColor(i); themed != nil {
+		return themed
+	}
+	return c
+}
+
+// SetPalette sets the colors of palette entries 0 to len(colors)-1, from the
+// 16 ANSI colors up to the 256-color cube and grays. A nil entry leaves that
+// color to the host terminal. Rows returned by GetScreen and ScrollbackRow
+// use these colors, and the child's OSC 4 queries are answered with them.
+// Colors the child sets with OSC 4 take precedence until it resets them.
+func (e *Emulator) SetPalette(colors []color.Color) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.palette = [256]color.Color{}
+	copy(e.palette[:], colors)
+	e.themeChanged()
+}
+
+// SetDefaultColors sets the default foreground, background and cursor colors,
+// which the child sees in answers to OSC 10, 11 and 12 queries and which
+// rows returned by GetScreen and ScrollbackRow are drawn in. A nil color is
+// left to the host terminal. Colors the child sets with OSC 10, 11 and 12 take
+// precedence until it resets them.
+func (e *Emulator) SetDefaultColors(fg, bg, cursor color.Color) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.defaultFg, e.defaultBg, e.defaultCursorColor = fg, bg, cursor
+	e.themeChanged()
+}
+
+// IndexedColor returns palette entry i (0 to 255) as the child sees it: set by
+// the child with OSC 4, configured with SetPalette or the standard xterm
+// color. It returns nil if i is out of range.
+func (e *Emulator) IndexedColor(i int) color.Color {
+	if i < 0 || i > 255 {
+		return nil
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.paletteColor(i)
+}
+
+// ForegroundColor returns the default foreground color, as set by the child
+// with OSC 10 or configured with SetDefaultColors, or nil when it is left to
+// the host terminal.
+func (e *Emulator) ForegroundColor() color.Color {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.foregroundColor()
+}
+
+// BackgroundColor returns the default background color, as set by the child
+// with OSC 11 or configured with SetDefaultColors, or nil when it is left to
+// the host terminal.
+func (e *Emulator) BackgroundColor() color.Color {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.backgroundColor()
+}
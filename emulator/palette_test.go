@@ -0,0 +1,85 @@
+package emulator
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestPaletteQueries(t *testing.T) {
+	e, input := newInputEmulator(t)
+
+	// Unconfigured entries report the xterm colors.
+	feed(e, "\x1b]4;1;?\x07")
+	if got := strings.Join(collectInput(input), ""); got != "\x1b]4;1;rgb:8080/0000/0000\x07" {
+		t.Fatalf("default palette reply %q", got)
+	}
+
+	e.SetPalette([]color.Color{nil, color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff}})
+	feed(e, "\x1b]4;1;?;2;?\x07")
+	want := "\x1b]4;1;rgb:1111/2222/3333\x07\x1b]4;2;rgb:0000/8080/0000\x07"
+	if got := strings.Join(collectInput(input), ""); got != want {
+		t.Fatalf("configured palette reply %q, want %q", got, want)
+	}
+
+	feed(e, "\x1b]4;1;rgb:ff/00/ff\x07\x1b]4;1;?\x07")
+	if got := strings.Join(collectInput(input), ""); got != "\x1b]4;1;rgb:ffff/0000/ffff\x07" {
+		t.Fatalf("reply after OSC 4 set %q", got)
+	}
+
+	feed(e, "\x1b]104;1\x07")
+	if !sameColor(e.IndexedColor(1), color.RGBA{R: 0x11, G: 0x22, B: 0x33, A: 0xff}) {
+		t.Fatalf("OSC 104 reset entry 1 to %v, want the configured color", e.IndexedColor(1))
+	}
+}
+
+func TestDefaultColorQueries(t *testing.T) {
+	e, input := newInputEmulator(t)
+
+	e.SetDefaultColors(color.RGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}, color.RGBA{R: 0x10, G: 0x10, B: 0x20, A: 0xff}, nil)
+	feed(e, "\x1b]10;?;?\x07")
+	want := "\x1b]10;rgb:eeee/eeee/eeee\x07\x1b]11;rgb:1010/1010/2020\x07"
+	if got := strings.Join(collectInput(input), ""); got != want {
+		t.Fatalf("OSC 10;?;? replied %q, want %q", got, want)
+	}
+
+	feed(e, "\x1b]11;#ffffff\x1b\\")
+	if !sameColor(e.BackgroundColor(), color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}) {
+		t.Fatalf("background %v after OSC 11", e.BackgroundColor())
+	}
+	feed(e, "\x1b]111\x07\x1b]11;?\x07")
+	if got := strings.Join(collectInput(input), ""); got != "\x1b]11;rgb:1010/1010/2020\x07" {
+		t.Fatalf("OSC 11 after reset replied %q", got)
+	}
+
+	feed(e, "\x1b]12;?\x07")
+	if got := strings.Join(collectInput(input), ""); got != "\x1b]12;rgb:ffff/ffff/ffff\x07" {
+		t.Fatalf("OSC 12 replied %q", got)
+	}
+}
+
+func TestScreenUsesPalette(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "\x1b[31mA\x1b[0mB")
+	if row := e.GetScreen().Rows[0]; !strings.Contains(row, "\x1b[31m") {
+		t.Fatalf("unthemed row %q does not use SGR 31", row)
+	}
+
+	e.SetPalette([]color.Color{nil, color.RGBA{R: 1, G: 2, B: 3, A: 0xff}})
+	e.SetDefaultColors(nil, color.RGBA{R: 4, G: 5, B: 6, A: 0xff}, color.RGBA{R: 7, G: 8, B: 9, A: 0xff})
+	frame := e.GetScreen()
+	if len(frame.Damage) != 4 {
+		t.Fatalf("got damage %+v, want every row redrawn", frame.Damage)
+	}
+	row := frame.Rows[0]
+	if !strings.Contains(row, "38;2;1;2;3") || !strings.Contains(row, "48;2;4;5;6") {
+		t.Fatalf("themed row %q does not use the palette and background", row)
+	}
+	if !strings.Contains(frame.Rows[3], "48;2;4;5;6") {
+		t.Fatalf("blank row %q is not drawn in the background color", frame.Rows[3])
+	}
+	if !sameColor(frame.Cursor.Color, color.RGBA{R: 7, G: 8, B: 9, A: 0xff}) {
+		t.Fatalf("cursor color %v, want the configured one", frame.Cursor.Color)
+	}
+}
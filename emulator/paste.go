@@ -0,0 +1,23 @@
+package emulator
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// pasteNewlines maps pasted line endings to carriage returns, which is what
+// the Enter key sends and what xterm does with pasted text.
+var pasteNewlines = strings.NewReplacer("\r\n", "\r", "\n", "\r")
+
+// Paste sends pasted text to the terminal as a single write. Line endings are
+// sent as carriage returns, and the text is wrapped in ESC[200~ / ESC[201~
+// when the child has enabled bracketed paste. Paste does not filter text; use
+// a sanitizer such as the model's to drop embedded end markers first.
+func (e *Emulator) Paste(text string) error {
+	text = pasteNewlines.Replace(text)
+	if e.IsBracketedPaste() {
+		text = ansi.BracketedPasteStart + text + ansi.BracketedPasteEnd
+	}
+	return e.SendKey(text)
+}
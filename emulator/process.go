@@ -0,0 +1,51 @@
+package emulator
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// ExitStatus describes how the process started with StartCommand ended.
+type ExitStatus struct {
+	ExitCode int            // Exit code, or -1 if the process was killed by a signal
+	Signal   syscall.Signal // Signal that killed the process, or 0
+	Err      error          // Error from waiting on the process, other than a non-zero exit
+	Duration time.Duration  // Time from start to exit
+}
+
+// exitStatusFrom builds the ExitStatus for a process that ran for d and whose
+// Wait returned err.
+func exitStatusFrom(cmd *exec.Cmd, err error, d time.Duration) ExitStatus {
+	status := ExitStatus{ExitCode: -1, Duration: d}
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		status.Err = err
+	}
+	if cmd.ProcessState == nil {
+		return status
+	}
+	status.ExitCode = cmd.ProcessState.ExitCode()
+	if ws, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		status.Signal = ws.Signal()
+	}
+	return status
+}
+
+// ExitStatus returns how the process ended. The second result is false while
+// the process is still running or if no process was started.
+func (e *Emulator) ExitStatus() (ExitStatus, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.exitStatus, e.processExited
+}
+
+// SetOnExitStatus sets a callback function that will be called with the exit
+// status when the process exits, alongside the callback set by SetOnExit. It
+// receives the emulator ID.
+func (e *Emulator) SetOnExitStatus(callback func(id string, status ExitStatus)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onExitStatus = callback
+}
@@ -0,0 +1,106 @@
+package emulator
+
+import (
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// runToExit starts cmd in a fresh emulator and returns the exit status passed
+// to the SetOnExitStatus callback.
+func runToExit(t *testing.T, cmd *exec.Cmd) (*Emulator, ExitStatus) {
+	t.Helper()
+	e, err := New(80, 24)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { e.Close() })
+
+	got := make(chan ExitStatus, 1)
+	e.SetOnExitStatus(func(id string, status ExitStatus) {
+		if id != e.ID() {
+			t.Errorf("callback got ID %q, want %q", id, e.ID())
+		}
+		got <- status
+	})
+
+	if _, ok := e.ExitStatus(); ok {
+		t.Fatal("expected no exit status before the process ran")
+	}
+	if err := e.StartCommand(cmd); err != nil {
+		t.Fatalf("StartCommand failed: %v", err)
+	}
+
+	select {
+	case status := <-got:
+		return e, status
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for exit status")
+	}
+	return nil, ExitStatus{}
+}
+
+func TestExitStatusCode(t *testing.T) {
+	e, status := runToExit(t, exec.Command("sh", "-c", "exit 3"))
+	if status.ExitCode != 3 || status.Signal != 0 || status.Err != nil {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status.Duration <= 0 {
+		t.Fatalf("expected a positive duration, got %v", status.Duration)
+	}
+
+	stored, ok := e.ExitStatus()
+	if !ok || stored != status {
+		t.Fatalf("ExitStatus() = %+v, %v; want %+v, true", stored, ok, status)
+	}
+}
+
+func TestExitStatusSignal(t *testing.T) {
+	_, status := runToExit(t, exec.Command("sh", "-c", "kill -TERM $$"))
+	if status.ExitCode != -1 || status.Signal != syscall.SIGTERM || status.Err != nil {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestReplacedProcessExitIsIgnored(t *testing.T) {
+	e, err := New(80, 24)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { e.Close() })
+
+	got := make(chan ExitStatus, 2)
+	e.SetOnExitStatus(func(id string, status ExitStatus) {
+		got <- status
+	})
+
+	first := exec.Command("sh", "-c", "sleep 0.2; exit 3")
+	if err := e.StartCommand(first); err != nil {
+		t.Fatalf("StartCommand failed: %v", err)
+	}
+	second := exec.Command("sh", "-c", "sleep 0.6; exit 5")
+	if err := e.StartCommand(second); err != nil {
+		t.Fatalf("StartCommand failed: %v", err)
+	}
+
+	// The first process exits while the second still runs.
+	time.Sleep(400 * time.Millisecond)
+	if e.IsProcessExited() {
+		t.Fatal("exit of the replaced process marked the new one exited")
+	}
+
+	select {
+	case status := <-got:
+		if status.ExitCode != 5 {
+			t.Fatalf("exit status = %+v, want the second process's", status)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for exit status")
+	}
+	select {
+	case status := <-got:
+		t.Fatalf("unexpected second exit status %+v", status)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
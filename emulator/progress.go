@@ -0,0 +1,94 @@
+package emulator
+
+import (
+	"bytes"
+	"strconv"
+)
+
+// ProgressState is the state of a progress bar reported with OSC 9;4.
+type ProgressState int
+
+const (
+	// ProgressNone means no progress is shown.
+	ProgressNone ProgressState = iota
+
+	// ProgressNormal means an operation is running with a known percent.
+	ProgressNormal
+
+	// ProgressError means the operation failed.
+	ProgressError
+
+	// ProgressIndeterminate means an operation is running with no known
+	// percent.
+	ProgressIndeterminate
+
+	// ProgressPaused means the operation is paused or needs attention.
+	ProgressPaused
+)
+
+// registerProgressHandlers hooks the vt parser to record ConEmu progress
+// reports, OSC 9 ; 4 ; st ; pr. Other OSC 9 payloads fall through to the
+// notification handler.
+func (e *Emulator) registerProgressHandlers() {
+	e.vt.RegisterOscHandler(9, func(data []byte) bool {
+		parts := bytes.Split(data, []byte{';'})
+		if len(parts) < 2 || string(parts[1]) != "4" {
+			return false
+		}
+		st := 0
+		if len(parts) > 2 {
+			st, _ = strconv.Atoi(string(parts[2]))
+		}
+		percent, hasPercent := 0, false
+		if len(parts) > 3 {
+			if pr, err := strconv.Atoi(string(parts[3])); err == nil {
+				percent, hasPercent = min(max(pr, 0), 100), true
+			}
+		}
+		e.setProgress(ProgressState(st), percent, hasPercent)
+		return true
+	})
+}
+
+// setProgress applies a progress report. Error and paused reports without a
+// percent keep the last one; none and indeterminate clear it. Must be called
+// with mu held.
+func (e *Emulator) setProgress(state ProgressState, percent int, hasPercent bool) {
+	switch state {
+	case ProgressNone, ProgressIndeterminate:
+		percent = 0
+	case ProgressNormal:
+	case ProgressError, ProgressPaused:
+		if !hasPercent {
+			percent = e.progressPercent
+		}
+	default:
+		return
+	}
+	if state == e.progressState && percent == e.progressPercent {
+		return
+	}
+	e.progressState, e.progressPercent = state, percent
+	if onProgress := e.onProgress; onProgress != nil {
+		id := e.id
+		e.queueEvent(func() { onProgress(id, state, percent) })
+	}
+}
+
+// Progress returns the progress the child last reported with OSC 9;4 and its
+// percent, from 0 to 100. The percent is 0 for ProgressNone and
+// ProgressIndeterminate.
+func (e *Emulator) Progress() (ProgressState, int) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.progressState, e.progressPercent
+}
+
+// SetOnProgress sets a callback function that will be called when the
+// progress the child reports with OSC 9;4 changes. It receives the emulator
+// ID, the new state and percent, and is called from the read loop goroutine.
+func (e *Emulator) SetOnProgress(callback func(id string, state ProgressState, percent int)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onProgress = callback
+}
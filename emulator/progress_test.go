@@ -0,0 +1,51 @@
+package emulator
+
+import "testing"
+
+func TestProgress(t *testing.T) {
+	e := newDamageEmulator(t)
+	type report struct {
+		state   ProgressState
+		percent int
+	}
+	var got []report
+	e.SetOnProgress(func(id string, state ProgressState, percent int) {
+		if id != e.ID() {
+			t.Errorf("callback got ID %q, want %q", id, e.ID())
+		}
+		got = append(got, report{state, percent})
+	})
+	notified := false
+	e.SetOnNotification(func(string, Notification) { notified = true })
+
+	steps := []struct {
+		seq  string
+		want report
+	}{
+		{"\x1b]9;4;1;40\x07", report{ProgressNormal, 40}},
+		{"\x1b]9;4;1;40\x07", report{ProgressNormal, 40}}, // unchanged
+		{"\x1b]9;4;2\x1b\\", report{ProgressError, 40}},   // keeps the percent
+		{"\x1b]9;4;4;150\x07", report{ProgressPaused, 100}},
+		{"\x1b]9;4;3;70\x07", report{ProgressIndeterminate, 0}},
+		{"\x1b]9;4;9;10\x07", report{ProgressIndeterminate, 0}}, // unknown state
+		{"\x1b]9;4;0\x07", report{ProgressNone, 0}},
+	}
+	for _, step := range steps {
+		feed(e, step.seq)
+		if state, percent := e.Progress(); (report{state, percent}) != step.want {
+			t.Fatalf("after %q: progress %v %d, want %+v", step.seq, state, percent, step.want)
+		}
+	}
+	if len(got) != 5 {
+		t.Errorf("got %d progress callbacks %+v, want 5", len(got), got)
+	}
+	if notified {
+		t.Error("progress report sent a notification")
+	}
+
+	// Other OSC 9 payloads are still notifications.
+	feed(e, "\x1b]9;done\x07")
+	if !notified {
+		t.Error("OSC 9 notification was not sent")
+	}
+}
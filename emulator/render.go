@@ -0,0 +1,137 @@
+package emulator
+
+import (
+	"slices"
+	"strings"
+
+	uv "github.com/charmbracelet/ultraviolet"
+)
+
+// rowChange describes how a row was redrawn by renderRows.
+type rowChange struct {
+	rendered bool // the row was touched, or the whole screen redrawn
+	x1, x2   int  // columns vt touched, half-open; the whole row on a full redraw
+	blank    bool // the row holds only spaces
+}
+
+// renderRows renders the rows the vt emulator marked as touched since the
+// previous call and reuses the others from the cached lastRows. Every row is
+// rendered when the cache is invalid, i.e. on the first frame and whenever the
+// whole screen is damaged (resize, screen switch, reset, RedrawAll), since vt
+// drops its touched state in those cases. Touched rows whose cells did not
+// change, including rows that only moved up as the screen scrolled, are
+// reused too. It returns the rows and what changed in each. Must be called
+// with mu held.
+func (e *Emulator) renderRows() ([]string, []rowChange) {
+	touched := e.vt.Touched()
+	full := e.fullDamage || len(e.lastRows) != e.height
+	if len(e.lastLines) != e.height || len(e.lastLines[0]) != e.width {
+		e.lastLines = make([][]uv.Cell, e.height)
+		for y := range e.lastLines {
+			e.lastLines[y] = make([]uv.Cell, e.width)
+		}
+		full = true
+	}
+	isTouched := func(y int) bool { return y < len(touched) && touched[y] != nil }
+
+	// Once the screen scrolled up k lines, row y shows what row y+k did, so
+	// compare it against that one. A scroll touches every row it moves.
+	prev := e.lastRows
+	k := e.scrolledLines - e.lastScrolled
+	e.lastScrolled = e.scrolledLines
+	if !full && k > 0 && k < e.height && len(touched) >= e.height && !slices.Contains(touched[:e.height], nil) {
+		prev = append(slices.Clone(prev[k:]), prev[:k]...)
+		e.lastLines = append(e.lastLines[k:], e.lastLines[:k]...)
+	}
+
+	redraw := make([]bool, e.height)
+	n := 0
+	for y := range redraw {
+		if full || (isTouched(y) && !e.sameLine(y)) {
+			redraw[y] = true
+			n++
+		}
+	}
+
+	// Rendering the buffer in one pass is cheaper than line by line once
+	// most rows are redrawn; themed rows need their cells.
+	var screen []string
+	if !e.themed && (full || 4*n >= 3*e.height) {
+		screen = e.renderScreen()
+	}
+
+	// Fresh outer slice: frames handed out earlier keep their rows intact.
+	rows := make([]string, e.height)
+	changes := make([]rowChange, e.height)
+	for y := range e.height {
+		if !full && !isTouched(y) {
+			rows[y] = e.lastRows[y]
+			continue
+		}
+		c := rowChange{rendered: true, x1: 0, x2: e.width}
+		line := e.lastLines[y]
+		switch {
+		case !redraw[y]:
+			rows[y] = prev[y]
+		case screen != nil:
+			rows[y] = screen[y]
+			e.readLine(y, line)
+		default:
+			rows[y] = renderRow(e.themeCells(e.readLine(y, line)), e.width)
+		}
+		c.blank = isBlankLine(line)
+		if !full {
+			c.x1, c.x2 = max(touched[y].FirstCell, 0), min(touched[y].LastCell, e.width)
+		}
+		changes[y] = c
+	}
+	clear(touched)
+
+	return rows, changes
+}
+
+// sameLine reports whether row y holds the cells lastLines recorded for it.
+// Must be called with mu held.
+func (e *Emulator) sameLine(y int) bool {
+	for x, cell := range e.lastLines[y] {
+		if c := e.vt.CellAt(x, y); c == nil || *c != cell {
+			return false
+		}
+	}
+	return true
+}
+
+// renderScreen renders every row of the screen buffer in one pass, padded to
+// the screen width. Must be called with mu held.
+func (e *Emulator) renderScreen() []string {
+	return splitIntoRows(e.vt.Render(), e.height, e.width)
+}
+
+// lineCells copies row y of the screen buffer. Must be called with mu held.
+func (e *Emulator) lineCells(y int) []uv.Cell {
+	return e.readLine(y, make([]uv.Cell, e.width))
+}
+
+// readLine copies row y of the screen buffer into line and returns it.
+// Must be called with mu held.
+func (e *Emulator) readLine(y int, line []uv.Cell) []uv.Cell {
+	// TODO: replace per-cell loop with vt.Line(y) once charmbracelet/x/vt
+	// exposes it (Screen.buf is private).
+	for x := range line {
+		if c := e.vt.CellAt(x, y); c != nil {
+			line[x] = *c
+		} else {
+			line[x] = uv.Cell{}
+		}
+	}
+	return line
+}
+
+// renderRow renders a line of cells with ANSI escape codes and pads it to
+// width.
+func renderRow(line uv.Line, width int) string {
+	if rendered := line.Render(); rendered != "" {
+		return padRow(rendered, width)
+	}
+	return strings.Repeat(" ", width)
+}
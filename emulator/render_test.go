@@ -0,0 +1,70 @@
+package emulator
+
+import (
+	"strings"
+	"testing"
+)
+
+// fullRenderRows renders the whole screen in one pass and splits it into
+// padded rows, as a reference for the per-row renderer. Must be called with
+// mu held.
+func fullRenderRows(e *Emulator) []string {
+	lines := strings.Split(e.vt.Render(), "\n")
+	rows := make([]string, e.height)
+	for y := range rows {
+		if y < len(lines) && lines[y] != "" {
+			rows[y] = padRow(lines[y], e.width)
+		} else {
+			rows[y] = strings.Repeat(" ", e.width)
+		}
+	}
+	return rows
+}
+
+// TestGetScreenIncrementalMatchesFullRender checks that the per-row cache
+// produces the same rows as rendering the whole screen after each write.
+func TestGetScreenIncrementalMatchesFullRender(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	steps := []string{
+		"\x1b[1;31mred\x1b[0m plain",
+		"\x1b[3;5H\x1b[4munder\x1b[0m",
+		"\x1b[2;1Hx",
+		"\r\n\r\n\r\nscrolled",
+		"\r\none\r\ntwo",
+		"\r\nthree\x1b[1;1Hedited", // scrolled rows reused, then one edited
+		"\x1b[1;1H\x1b[2K",
+		"\x1b[2;3H\x1b[1P",
+		"\x1b[?1049hworld",
+		"\x1b[?1049l",
+		"\x1bc",
+		"wide 世界",
+	}
+	for _, step := range steps {
+		feed(e, step)
+		frame := e.GetScreen()
+
+		e.mu.Lock()
+		want := fullRenderRows(e)
+		e.mu.Unlock()
+		for y := range want {
+			if frame.Rows[y] != want[y] {
+				t.Fatalf("after %q row %d = %q, want %q", step, y, frame.Rows[y], want[y])
+			}
+		}
+	}
+}
+
+func TestGetScreenKeepsEarlierFrames(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "first")
+	first := e.GetScreen()
+	row := first.Rows[0]
+
+	feed(e, "\rsecond")
+	e.GetScreen()
+	if first.Rows[0] != row {
+		t.Fatalf("earlier frame changed: %q, want %q", first.Rows[0], row)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/charmbracelet/x/vt"
 )
 
@@ -499,6 +500,52 @@ func TestPadRowANSIAwareWidth(t *testing.T) {
 	}
 }
 
+func TestRenderScreen(t *testing.T) {
+	e, err := New(10, 5)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Close()
+
+	e.mu.Lock()
+	e.vt.Write([]byte("line1\r\nline2\r\nline3"))
+	rows := e.renderScreen()
+	e.mu.Unlock()
+
+	if len(rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(rows))
+	}
+	for i, want := range []string{"line1", "line2", "line3"} {
+		if !strings.Contains(rows[i], want) {
+			t.Errorf("row %d = %q, want to contain %q", i, rows[i], want)
+		}
+	}
+	// Remaining rows should be spaces
+	expected := strings.Repeat(" ", 10)
+	for i := 3; i < 5; i++ {
+		if rows[i] != expected {
+			t.Errorf("row %d = %q, want %q", i, rows[i], expected)
+		}
+	}
+}
+
+func TestRowWidthMatchesStringWidth(t *testing.T) {
+	rows := []string{
+		"",
+		"plain text",
+		"\x1b[32;1mrow content\x1b[m",
+		"\x1b]8;;https://example.com\x07link\x1b]8;;\x07 after",
+		"\x1b]8;id=1;https://example.com\x1b\\link\x1b]8;;\x1b\\",
+		"\x1b[31m世界\x1b[m wide",
+		"emoji 👍🏽 text",
+	}
+	for _, row := range rows {
+		if got, want := rowWidth(row), ansi.StringWidth(row); got != want {
+			t.Errorf("rowWidth(%q) = %d, want %d", row, got, want)
+		}
+	}
+}
+
 func TestGetScreenReturnsCachedRowsWhenUndamaged(t *testing.T) {
 	e, err := New(10, 5)
 	if err != nil {
@@ -599,6 +646,72 @@ func BenchmarkGetScreenDamagedContent(b *testing.B) {
 	}
 }
 
+// BenchmarkGetScreenDamagedContentFullRedraw redraws the whole screen full
+// of attributed (SGR) text per frame, as after a resize or RedrawAll, so
+// renderScreen/padRow do real work on every row.
+func BenchmarkGetScreenDamagedContentFullRedraw(b *testing.B) {
+	e := newContentEmulator(b)
+
+	for b.Loop() {
+		e.mu.Lock()
+		e.damageAll(CRRedraw)
+		e.mu.Unlock()
+		e.GetScreen()
+	}
+}
+
+// BenchmarkGetScreenDamagedContentOneCell changes a single character per
+// frame, the common case for a tailing log or a ticking clock, where only the
+// touched row is re-rendered.
+func BenchmarkGetScreenDamagedContentOneCell(b *testing.B) {
+	e := newContentEmulator(b)
+
+	var i int
+	for b.Loop() {
+		e.mu.Lock()
+		e.vt.Write([]byte("\x1b[12;40H" + strconv.Itoa(i%10)))
+		e.markDamaged()
+		e.mu.Unlock()
+		e.GetScreen()
+		i++
+	}
+}
+
+// BenchmarkGetScreenDamagedContentScroll scrolls one new line in per frame,
+// which touches every row.
+func BenchmarkGetScreenDamagedContentScroll(b *testing.B) {
+	e := newContentEmulator(b)
+
+	for b.Loop() {
+		e.mu.Lock()
+		e.vt.Write([]byte("\x1b[24;1H\r\n\x1b[1;32mrow content with attrs\x1b[0m"))
+		e.markDamaged()
+		e.mu.Unlock()
+		e.GetScreen()
+	}
+}
+
+// newContentEmulator returns an 80x24 emulator whose screen is full of
+// attributed (SGR) text, so rendering and padding do real work instead of
+// returning blank rows. The initial frame has been consumed.
+func newContentEmulator(b *testing.B) *Emulator {
+	b.Helper()
+	e, err := New(80, 24)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { e.Close() })
+
+	e.mu.Lock()
+	for row := range 24 {
+		// Move cursor, set a color, write a partial line so padding kicks in.
+		e.vt.Write([]byte("\x1b[" + strconv.Itoa(row+1) + ";1H\x1b[1;32mrow content with attrs\x1b[0m"))
+	}
+	e.mu.Unlock()
+	e.GetScreen()
+	return e
+}
+
 func TestEmulatorResizeMarksDamage(t *testing.T) {
 	e, err := New(80, 24)
 	if err != nil {
@@ -821,3 +934,29 @@ func TestCellAtStyle(t *testing.T) {
 		t.Error("expected foreground color set")
 	}
 }
+
+func TestIsMouseTracking(t *testing.T) {
+	e, err := New(10, 5)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Close()
+
+	if e.IsMouseTracking() {
+		t.Fatal("expected mouse tracking to be off initially")
+	}
+
+	e.mu.Lock()
+	e.vt.Write([]byte("\x1b[?1002h"))
+	e.mu.Unlock()
+	if !e.IsMouseTracking() {
+		t.Fatal("expected mouse tracking after DECSET 1002")
+	}
+
+	e.mu.Lock()
+	e.vt.Write([]byte("\x1b[?1002l"))
+	e.mu.Unlock()
+	if e.IsMouseTracking() {
+		t.Fatal("expected mouse tracking off after DECRST 1002")
+	}
+}
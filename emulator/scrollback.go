@@ -0,0 +1,166 @@
+package emulator
+
+import (
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// DefaultScrollbackSize is the number of lines kept in the scrollback buffer
+// unless changed with SetScrollbackSize.
+const DefaultScrollbackSize = 10000
+
+// scrollbackHeadroom is how far the vt scrollback may grow past the configured
+// size before syncScrollback trims it. Trimming it ourselves instead of
+// letting vt drop the oldest lines keeps every line that scrolls off the
+// screen countable, which anchors like shell integration marks rely on.
+const scrollbackHeadroom = 4096
+
+// registerScrollbackHandlers hooks the vt parser so that scrollback follows
+// xterm semantics.
+func (e *Emulator) registerScrollbackHandlers() {
+	// ED 3 (CSI 3 J) erases the saved lines. The vt emulator only clears the
+	// scrollback of the active screen, which misses the main screen's
+	// history when the child is on the alternate screen, so clear it here and
+	// let the default handler erase the display.
+	e.vt.RegisterCsiHandler('J', func(params ansi.Params) bool {
+		if n, _, _ := params.Param(0, 0); n == 3 {
+			e.clearScrollback()
+		}
+		return false
+	})
+}
+
+// SetScrollbackSize sets the maximum number of lines kept in the scrollback
+// buffer. When the buffer holds more lines than the new limit, the oldest
+// lines are dropped.
+func (e *Emulator) SetScrollbackSize(lines int) error {
+	if lines < 1 {
+		return ErrInvalidSize
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.scrollbackSize = lines
+	e.vt.SetScrollbackSize(lines + scrollbackHeadroom)
+	e.syncScrollback()
+	return nil
+}
+
+// ScrollbackSize returns the maximum number of lines kept in the scrollback
+// buffer.
+func (e *Emulator) ScrollbackSize() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.scrollbackSize
+}
+
+// ScrollbackLen returns the number of lines currently in the scrollback buffer.
+func (e *Emulator) ScrollbackLen() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.vt.ScrollbackLen()
+}
+
+// ScrollbackDropped returns the number of lines dropped from the top of the
+// scrollback buffer so far, as the oldest lines beyond ScrollbackSize or when
+// it is cleared. Scrollback indexes of the remaining lines shift down by as
+// many, so callers holding on to an index can follow its line.
+func (e *Emulator) ScrollbackDropped() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.historyStart()
+}
+
+// ScrollbackLine returns a copy of the cells of scrollback line i, where 0 is
+// the oldest line and ScrollbackLen()-1 the line that most recently scrolled
+// off the top of the screen. Trailing blank cells are trimmed, so the result
+// may be shorter than the screen width. It returns nil if i is out of range.
+func (e *Emulator) ScrollbackLine(i int) []uv.Cell {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	line := e.vt.Scrollback().Line(i)
+	if line == nil {
+		return nil
+	}
+	cells := make([]uv.Cell, len(line))
+	copy(cells, line)
+	return cells
+}
+
+// ScrollbackRow returns scrollback line i rendered with ANSI escape codes and
+// padded to the screen width, in the same format as EmittedFrame.Rows. It
+// returns an empty string if i is out of range.
+func (e *Emulator) ScrollbackRow(i int) string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	line := e.vt.Scrollback().Line(i)
+	if line == nil {
+		return ""
+	}
+	if len(line) > e.width {
+		line = line[:e.width]
+	}
+	if e.backgroundColor() != nil && len(line) < e.width {
+		// Pad with blank cells so the default background covers the row.
+		padded := make([]uv.Cell, e.width)
+		copy(padded, line)
+		for x := len(line); x < e.width; x++ {
+			padded[x] = uv.EmptyCell
+		}
+		line = padded
+	}
+	return renderRow(e.themeCells(line), e.width)
+}
+
+// ClearScrollback discards all lines in the scrollback buffer.
+func (e *Emulator) ClearScrollback() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.clearScrollback()
+}
+
+// clearScrollback discards the scrollback, counting the lines that scrolled
+// into it first. Must be called with mu held.
+func (e *Emulator) clearScrollback() {
+	e.syncScrollback()
+	e.vt.ClearScrollback()
+	e.scrollbackSeen = 0
+}
+
+// syncScrollback counts the lines that scrolled into the scrollback since the
+// last call and trims it to the configured size. Must be called with mu held,
+// after anything that may scroll the main screen.
+func (e *Emulator) syncScrollback() {
+	sb := e.vt.Scrollback()
+	n := sb.Len()
+	if n > e.scrollbackSeen {
+		e.scrolledLines += n - e.scrollbackSeen
+	}
+	if n > e.scrollbackSize {
+		sb.SetMaxLines(e.scrollbackSize)
+		sb.SetMaxLines(e.scrollbackSize + scrollbackHeadroom)
+		n = e.scrollbackSize
+	}
+	e.scrollbackSeen = n
+}
+
+// historyLine returns the absolute history line of screen row y: the number
+// of lines that ever scrolled off the main screen, plus y. Unlike scrollback
+// indexes, it does not change as old lines are dropped. Must be called with
+// mu held.
+func (e *Emulator) historyLine(y int) int {
+	e.syncScrollback()
+	return e.scrolledLines + y
+}
+
+// scrollbackIndex converts an absolute history line to a scrollback index,
+// where ScrollbackLen and above are screen rows. Lines dropped from the
+// scrollback are negative. Must be called with mu held.
+func (e *Emulator) scrollbackIndex(line int) int {
+	return line - e.historyStart()
+}
+
+// historyStart returns the absolute history line of the oldest line in the
+// scrollback. Must be called with mu held.
+func (e *Emulator) historyStart() int {
+	return e.scrolledLines - e.scrollbackSeen
+}
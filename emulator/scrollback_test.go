@@ -0,0 +1,143 @@
+package emulator
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeLines feeds n numbered lines through the vt emulator so the oldest
+// ones scroll off the top of the screen.
+func writeLines(e *Emulator, n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range n {
+		e.vt.Write([]byte("line" + strconv.Itoa(i) + "\r\n"))
+	}
+	e.markDamaged()
+}
+
+func TestScrollbackCollectsScrolledLines(t *testing.T) {
+	e, err := New(10, 3)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Close()
+
+	writeLines(e, 5)
+
+	// 5 lines plus the trailing newline leave "line3", "line4" and the
+	// empty cursor row on screen, so three lines scrolled off.
+	if got := e.ScrollbackLen(); got != 3 {
+		t.Fatalf("ScrollbackLen() = %d, want 3", got)
+	}
+
+	cells := e.ScrollbackLine(0)
+	var content strings.Builder
+	for _, c := range cells {
+		content.WriteString(c.Content)
+	}
+	if content.String() != "line0" {
+		t.Errorf("ScrollbackLine(0) = %q, want %q", content.String(), "line0")
+	}
+
+	row := e.ScrollbackRow(2)
+	if !strings.HasPrefix(row, "line2") {
+		t.Errorf("ScrollbackRow(2) = %q, want prefix %q", row, "line2")
+	}
+	if !strings.HasSuffix(row, "\x1b[0m     ") {
+		t.Errorf("ScrollbackRow(2) = %q, want SGR reset and padding to width", row)
+	}
+
+	if e.ScrollbackLine(3) != nil || e.ScrollbackLine(-1) != nil {
+		t.Error("expected nil for out-of-range scrollback lines")
+	}
+	if e.ScrollbackRow(3) != "" {
+		t.Error("expected empty row for out-of-range scrollback line")
+	}
+}
+
+func TestScrollbackRowRendersStyles(t *testing.T) {
+	e, err := New(10, 2)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Close()
+
+	e.mu.Lock()
+	e.vt.Write([]byte("\x1b[1;31mred\x1b[0m\r\n\r\n"))
+	e.mu.Unlock()
+
+	if e.ScrollbackLen() != 1 {
+		t.Fatalf("ScrollbackLen() = %d, want 1", e.ScrollbackLen())
+	}
+	row := e.ScrollbackRow(0)
+	if !strings.Contains(row, "\x1b[") || !strings.Contains(row, "red") {
+		t.Errorf("ScrollbackRow(0) = %q, want styled content", row)
+	}
+}
+
+func TestSetScrollbackSize(t *testing.T) {
+	e, err := New(10, 2)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Close()
+
+	if got := e.ScrollbackSize(); got != DefaultScrollbackSize {
+		t.Fatalf("ScrollbackSize() = %d, want %d", got, DefaultScrollbackSize)
+	}
+
+	if err := e.SetScrollbackSize(0); err != ErrInvalidSize {
+		t.Fatalf("SetScrollbackSize(0) error = %v, want ErrInvalidSize", err)
+	}
+
+	writeLines(e, 10)
+	if err := e.SetScrollbackSize(4); err != nil {
+		t.Fatalf("SetScrollbackSize(4) failed: %v", err)
+	}
+	if got := e.ScrollbackLen(); got != 4 {
+		t.Fatalf("ScrollbackLen() = %d after shrinking, want 4", got)
+	}
+	// The oldest lines are dropped first.
+	if row := e.ScrollbackRow(3); !strings.HasPrefix(row, "line8") {
+		t.Errorf("ScrollbackRow(3) = %q, want prefix %q", row, "line8")
+	}
+}
+
+func TestScrollbackClearedOnED3(t *testing.T) {
+	e, err := New(10, 3)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Close()
+
+	writeLines(e, 6)
+	if e.ScrollbackLen() == 0 {
+		t.Fatal("expected scrollback before ED 3")
+	}
+
+	// Switch to the alternate screen first: ED 3 must still clear the main
+	// screen's history.
+	e.mu.Lock()
+	e.vt.Write([]byte("\x1b[?1049h\x1b[3J"))
+	e.mu.Unlock()
+
+	if got := e.ScrollbackLen(); got != 0 {
+		t.Fatalf("ScrollbackLen() = %d after ED 3, want 0", got)
+	}
+}
+
+func TestClearScrollback(t *testing.T) {
+	e, err := New(10, 3)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Close()
+
+	writeLines(e, 6)
+	e.ClearScrollback()
+	if got := e.ScrollbackLen(); got != 0 {
+		t.Fatalf("ScrollbackLen() = %d after ClearScrollback, want 0", got)
+	}
+}
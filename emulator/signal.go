@@ -0,0 +1,132 @@
+package emulator
+
+import (
+	"context"
+	"errors"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// foregroundPgid returns the foreground process group of the PTY, as
+// tcgetpgrp(3) would. Must be called with mu held.
+func (e *Emulator) foregroundPgid() (int, error) {
+	if e.pty == nil {
+		return 0, ErrPTYNotInitialized
+	}
+	// Use the raw connection rather than Fd, which would switch the PTY to
+	// blocking mode and keep Close from interrupting the read loop.
+	rc, err := e.pty.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var pgid int
+	var ioctlErr error
+	if err := rc.Control(func(fd uintptr) {
+		pgid, ioctlErr = unix.IoctlGetInt(int(fd), unix.TIOCGPGRP)
+	}); err != nil {
+		return 0, err
+	}
+	return pgid, ioctlErr
+}
+
+// runningPid returns the pid of the process started with StartCommand, or
+// ErrNoProcess. Must be called with mu held.
+func (e *Emulator) runningPid() (int, error) {
+	if e.isPipe || e.cmd == nil || e.cmd.Process == nil || e.exited == nil {
+		return 0, ErrNoProcess
+	}
+	return e.cmd.Process.Pid, nil
+}
+
+// Signal sends sig to the foreground process group of the terminal, i.e. the
+// job that would receive the signal if the user typed Ctrl+C (SIGINT),
+// Ctrl+Z (SIGTSTP) or Ctrl+\ (SIGQUIT). Use SIGCONT to resume a stopped job.
+// When the foreground group cannot be determined, the process group of the
+// started process is signaled instead. It returns ErrNoProcess if no process
+// was started or it has already exited.
+func (e *Emulator) Signal(sig syscall.Signal) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	pid, err := e.runningPid()
+	if err != nil {
+		return err
+	}
+	if e.processExited {
+		return ErrNoProcess
+	}
+	pgid, err := e.foregroundPgid()
+	if err != nil || pgid <= 0 {
+		pgid = pid
+	}
+	return syscall.Kill(-pgid, sig)
+}
+
+// signalAll sends sig to the process group of the started process and to the
+// foreground process group, ignoring groups that no longer exist.
+func (e *Emulator) signalAll(sig syscall.Signal) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	pid, err := e.runningPid()
+	if err != nil {
+		return err
+	}
+	if e.processExited {
+		return nil
+	}
+	groups := []int{pid} // Setsid makes the process its own group leader
+	if pgid, err := e.foregroundPgid(); err == nil && pgid > 0 && pgid != pid {
+		groups = append(groups, pgid)
+	}
+	for _, pgid := range groups {
+		if err := syscall.Kill(-pgid, sig); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Wait blocks until the process started with StartCommand exits and returns
+// its exit status. It returns ctx's error if ctx is done first, and
+// ErrNoProcess if no process was started.
+func (e *Emulator) Wait(ctx context.Context) (ExitStatus, error) {
+	e.mu.RLock()
+	exited := e.exited
+	e.mu.RUnlock()
+	if exited == nil {
+		return ExitStatus{}, ErrNoProcess
+	}
+
+	select {
+	case <-exited:
+		status, _ := e.ExitStatus()
+		return status, nil
+	case <-ctx.Done():
+		return ExitStatus{}, ctx.Err()
+	}
+}
+
+// Terminate stops the process started with StartCommand without closing the
+// emulator. It sends SIGTERM (followed by SIGCONT, so stopped jobs can act on
+// it) to the process and to the terminal's foreground job, waits for the
+// process to exit, and escalates to SIGKILL once ctx is done. It returns the
+// exit status.
+func (e *Emulator) Terminate(ctx context.Context) (ExitStatus, error) {
+	if err := e.signalAll(syscall.SIGTERM); err != nil {
+		return ExitStatus{}, err
+	}
+	if err := e.signalAll(syscall.SIGCONT); err != nil {
+		return ExitStatus{}, err
+	}
+
+	status, err := e.Wait(ctx)
+	if err == nil {
+		return status, nil
+	}
+	if err := e.signalAll(syscall.SIGKILL); err != nil {
+		return ExitStatus{}, err
+	}
+	return e.Wait(context.Background())
+}
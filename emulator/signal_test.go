@@ -0,0 +1,107 @@
+package emulator
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// startProcess starts cmd in a fresh emulator and gives the child a moment to
+// install its signal dispositions.
+func startProcess(t *testing.T, cmd *exec.Cmd) *Emulator {
+	t.Helper()
+	e, err := New(80, 24)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { e.Close() })
+
+	if err := e.StartCommand(cmd); err != nil {
+		t.Fatalf("StartCommand failed: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	return e
+}
+
+func waitExit(t *testing.T, e *Emulator) ExitStatus {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	status, err := e.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	return status
+}
+
+func TestSignalForegroundGroup(t *testing.T) {
+	e := startProcess(t, exec.Command("sleep", "10"))
+	if err := e.Signal(syscall.SIGINT); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	if status := waitExit(t, e); status.Signal != syscall.SIGINT {
+		t.Fatalf("expected SIGINT, got %+v", status)
+	}
+	if err := e.Signal(syscall.SIGINT); !errors.Is(err, ErrNoProcess) {
+		t.Fatalf("Signal after exit = %v, want ErrNoProcess", err)
+	}
+}
+
+func TestWaitContext(t *testing.T) {
+	e := startProcess(t, exec.Command("sleep", "10"))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := e.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, want DeadlineExceeded", err)
+	}
+	if e.IsProcessExited() {
+		t.Fatal("process should still be running")
+	}
+}
+
+func TestTerminate(t *testing.T) {
+	e := startProcess(t, exec.Command("sleep", "10"))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	status, err := e.Terminate(ctx)
+	if err != nil {
+		t.Fatalf("Terminate failed: %v", err)
+	}
+	if status.Signal != syscall.SIGTERM {
+		t.Fatalf("expected SIGTERM, got %+v", status)
+	}
+}
+
+func TestTerminateEscalates(t *testing.T) {
+	e := startProcess(t, exec.Command("sh", "-c", `trap "" TERM; while :; do sleep 0.05; done`))
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	status, err := e.Terminate(ctx)
+	if err != nil {
+		t.Fatalf("Terminate failed: %v", err)
+	}
+	if status.Signal != syscall.SIGKILL {
+		t.Fatalf("expected SIGKILL, got %+v", status)
+	}
+}
+
+func TestSignalWithoutProcess(t *testing.T) {
+	e, err := New(80, 24)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer e.Close()
+
+	if err := e.Signal(syscall.SIGINT); !errors.Is(err, ErrNoProcess) {
+		t.Fatalf("Signal = %v, want ErrNoProcess", err)
+	}
+	if _, err := e.Wait(context.Background()); !errors.Is(err, ErrNoProcess) {
+		t.Fatalf("Wait = %v, want ErrNoProcess", err)
+	}
+	if _, err := e.Terminate(context.Background()); !errors.Is(err, ErrNoProcess) {
+		t.Fatalf("Terminate = %v, want ErrNoProcess", err)
+	}
+}
@@ -0,0 +1,77 @@
+package emulator
+
+import (
+	"io"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxSyncDuration is how long the child may hold back frames with
+// synchronized output (mode 2026) before the screen is shown anyway, so a
+// child that never ends its update does not freeze the terminal.
+const maxSyncDuration = 150 * time.Millisecond
+
+// registerSyncHandlers hooks the vt parser to support synchronized output,
+// which vt does not implement.
+func (e *Emulator) registerSyncHandlers() {
+	// DECRQM (CSI ? Ps $ p) for mode 2026 reports it as supported; vt
+	// reports modes it has never seen as not recognized.
+	e.vt.RegisterCsiHandler(ansi.Command('?', '$', 'p'), func(params ansi.Params) bool {
+		n, _, _ := params.Param(0, 0)
+		if n != int(ansi.ModeSynchronizedOutput) {
+			return false
+		}
+		setting := ansi.ModeReset
+		if e.isModeSet(ansi.ModeSynchronizedOutput) {
+			setting = ansi.ModeSet
+		}
+		io.WriteString(e.vt.InputPipe(), ansi.ReportMode(ansi.ModeSynchronizedOutput, setting))
+		return true
+	})
+
+	// RIS (ESC c) resets the mode without firing callbacks, so end the
+	// update here; the mode itself is reset with the rest of the mirror.
+	e.vt.RegisterEscHandler('c', func() bool {
+		e.setSyncOutput(false)
+		return false
+	})
+}
+
+// setSyncOutput starts or ends a synchronized update. While one is active,
+// damage is recorded but neither signaled on NotifyChanged nor returned by
+// GetScreen, so consumers never see a half-drawn frame. Must be called with
+// mu held.
+func (e *Emulator) setSyncOutput(on bool) {
+	if on == e.syncing {
+		return
+	}
+	e.syncing = on
+	e.syncGen++
+	if e.syncTimer != nil {
+		e.syncTimer.Stop()
+		e.syncTimer = nil
+	}
+	if !on {
+		if e.damaged {
+			e.markDamaged()
+		}
+		return
+	}
+	gen := e.syncGen
+	e.syncTimer = time.AfterFunc(maxSyncDuration, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.syncing && e.syncGen == gen {
+			e.setSyncOutput(false)
+		}
+	})
+}
+
+// IsSynchronizedOutput reports whether the child is in the middle of a
+// synchronized update (mode 2026), during which frames are held back.
+func (e *Emulator) IsSynchronizedOutput() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.syncing
+}
@@ -0,0 +1,76 @@
+package emulator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// drainNotify discards a pending change notification.
+func drainNotify(e *Emulator) {
+	select {
+	case <-e.NotifyChanged():
+	default:
+	}
+}
+
+func TestSynchronizedOutputHoldsFrames(t *testing.T) {
+	e := newDamageEmulator(t)
+	drainNotify(e)
+
+	feed(e, "\x1b[?2026hhalf")
+	if !e.IsSynchronizedOutput() {
+		t.Fatal("mode 2026 did not start a synchronized update")
+	}
+	select {
+	case <-e.NotifyChanged():
+		t.Fatal("change notified during a synchronized update")
+	default:
+	}
+	if frame := e.GetScreen(); len(frame.Damage) != 0 || strings.Contains(frame.Rows[0], "half") {
+		t.Fatalf("GetScreen returned a half-drawn frame %q", frame.Rows)
+	}
+
+	feed(e, " done\x1b[?2026l")
+	select {
+	case <-e.NotifyChanged():
+	default:
+		t.Fatal("end of the synchronized update was not notified")
+	}
+	if frame := e.GetScreen(); len(frame.Damage) == 0 || !strings.Contains(frame.Rows[0], "half done") {
+		t.Fatalf("frame after the update %q, damage %+v", frame.Rows, frame.Damage)
+	}
+}
+
+func TestSynchronizedOutputTimeout(t *testing.T) {
+	e := newDamageEmulator(t)
+	drainNotify(e)
+
+	feed(e, "\x1b[?2026hstuck")
+	select {
+	case <-e.NotifyChanged():
+	case <-time.After(10 * maxSyncDuration):
+		t.Fatal("synchronized update never timed out")
+	}
+	if e.IsSynchronizedOutput() {
+		t.Fatal("synchronized update still active after the timeout")
+	}
+	if frame := e.GetScreen(); !strings.Contains(frame.Rows[0], "stuck") {
+		t.Fatalf("frame after the timeout %q", frame.Rows)
+	}
+}
+
+func TestSynchronizedOutputReport(t *testing.T) {
+	e, input := newInputEmulator(t)
+
+	feed(e, "\x1b[?2026$p")
+	if got := collectInput(input); !slices.Equal(got, []string{"\x1b[?2026;2$y"}) {
+		t.Fatalf("DECRQM 2026 replied %q, want reset", got)
+	}
+	feed(e, "\x1b[?2026h\x1b[?2026$p")
+	if got := collectInput(input); !slices.Equal(got, []string{"\x1b[?2026;1$y"}) {
+		t.Fatalf("DECRQM 2026 replied %q, want set", got)
+	}
+	feed(e, "\x1b[?2026l")
+}
@@ -0,0 +1,116 @@
+package emulator
+
+import (
+	"bytes"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// maxTitleStack is the depth of the title stack, matching xterm.
+const maxTitleStack = 10
+
+// titleEntry is a saved window title and icon name (CSI 22 t).
+type titleEntry struct {
+	title, iconName string
+}
+
+// registerTitleHandlers hooks the vt parser to track the window title and
+// icon name.
+func (e *Emulator) registerTitleHandlers() {
+	// OSC 0/1/2 set the icon name and/or window title. The vt emulator drops
+	// titles containing ';', so parse them here.
+	for _, cmd := range []int{0, 1, 2} {
+		e.vt.RegisterOscHandler(cmd, func(data []byte) bool {
+			_, name, ok := bytes.Cut(data, []byte{';'})
+			if !ok {
+				return true
+			}
+			if cmd == 0 || cmd == 1 {
+				e.iconName = string(name)
+			}
+			if cmd == 0 || cmd == 2 {
+				e.setTitle(string(name))
+			}
+			return true
+		})
+	}
+
+	// XTWINOPS 22/23 (CSI 22 ; Ps t, CSI 23 ; Ps t) push and pop the title
+	// and icon name. On pop, Ps selects what is restored: 0 for both, 1 for
+	// the icon name and 2 for the title. Other window operations fall through
+	// to vt.
+	e.vt.RegisterCsiHandler('t', func(params ansi.Params) bool {
+		op, _, _ := params.Param(0, 0)
+		switch op {
+		case 22:
+			e.pushTitle()
+		case 23:
+			which, _, _ := params.Param(1, 0)
+			e.popTitle(which)
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+// pushTitle saves the title and icon name. Must be called with mu held.
+func (e *Emulator) pushTitle() {
+	entry := titleEntry{title: e.title, iconName: e.iconName}
+	if len(e.titleStack) == maxTitleStack {
+		e.titleStack = e.titleStack[1:]
+	}
+	e.titleStack = append(e.titleStack, entry)
+}
+
+// popTitle restores the title and/or icon name saved by pushTitle. Must be
+// called with mu held.
+func (e *Emulator) popTitle(which int) {
+	if len(e.titleStack) == 0 {
+		return
+	}
+	entry := e.titleStack[len(e.titleStack)-1]
+	e.titleStack = e.titleStack[:len(e.titleStack)-1]
+	if which == 0 || which == 1 {
+		e.iconName = entry.iconName
+	}
+	if which == 0 || which == 2 {
+		e.setTitle(entry.title)
+	}
+}
+
+// setTitle updates the window title and queues the title-change callback.
+// Must be called with mu held.
+func (e *Emulator) setTitle(title string) {
+	if title == e.title {
+		return
+	}
+	e.title = title
+	if onTitle := e.onTitle; onTitle != nil {
+		id := e.id
+		e.queueEvent(func() { onTitle(id, title) })
+	}
+}
+
+// Title returns the window title last set by the child with OSC 0 or OSC 2.
+func (e *Emulator) Title() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.title
+}
+
+// IconName returns the icon name last set by the child with OSC 0 or OSC 1.
+func (e *Emulator) IconName() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.iconName
+}
+
+// SetOnTitleChange sets a callback function that will be called when the
+// window title changes. It receives the emulator ID and the new title, and is
+// called from the read loop goroutine.
+func (e *Emulator) SetOnTitleChange(callback func(id, title string)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.onTitle = callback
+}
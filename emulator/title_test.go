@@ -0,0 +1,80 @@
+package emulator
+
+import (
+	"testing"
+)
+
+func TestTitleFromOSC(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "\x1b]0;both\x07")
+	if e.Title() != "both" || e.IconName() != "both" {
+		t.Fatalf("after OSC 0: title %q icon %q", e.Title(), e.IconName())
+	}
+
+	feed(e, "\x1b]2;vim; main.go\x07")
+	if e.Title() != "vim; main.go" {
+		t.Fatalf("after OSC 2: title %q, want %q", e.Title(), "vim; main.go")
+	}
+	if e.IconName() != "both" {
+		t.Fatalf("OSC 2 changed the icon name to %q", e.IconName())
+	}
+
+	feed(e, "\x1b]1;icon\x1b\\")
+	if e.IconName() != "icon" || e.Title() != "vim; main.go" {
+		t.Fatalf("after OSC 1: title %q icon %q", e.Title(), e.IconName())
+	}
+}
+
+func TestTitleStack(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	feed(e, "\x1b]0;shell\x07\x1b[22;0t\x1b]0;vim\x07")
+	if e.Title() != "vim" {
+		t.Fatalf("title %q, want vim", e.Title())
+	}
+
+	feed(e, "\x1b[23;2t")
+	if e.Title() != "shell" || e.IconName() != "vim" {
+		t.Fatalf("after pop of title: title %q icon %q", e.Title(), e.IconName())
+	}
+
+	// Popping an empty stack is a no-op.
+	feed(e, "\x1b[23;0t")
+	if e.Title() != "shell" {
+		t.Fatalf("title %q after popping an empty stack", e.Title())
+	}
+}
+
+func TestTitleStackDepth(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	for range maxTitleStack + 5 {
+		feed(e, "\x1b[22t")
+	}
+	e.mu.RLock()
+	depth := len(e.titleStack)
+	e.mu.RUnlock()
+	if depth != maxTitleStack {
+		t.Fatalf("title stack depth %d, want %d", depth, maxTitleStack)
+	}
+}
+
+func TestOnTitleChange(t *testing.T) {
+	e := newDamageEmulator(t)
+
+	var got []string
+	e.SetOnTitleChange(func(id, title string) {
+		if id != e.ID() {
+			t.Errorf("callback got ID %q, want %q", id, e.ID())
+		}
+		// Calling back into the emulator must not deadlock.
+		_ = e.Title()
+		got = append(got, title)
+	})
+
+	feed(e, "\x1b]2;one\x07\x1b]2;one\x07\x1b]2;two\x07")
+	if len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Fatalf("callback titles %q, want [one two]", got)
+	}
+}
@@ -1,5 +1,7 @@
 package emulator
 
+import "image/color"
+
 // ChangeReason says what kind of change caused the region to change, for optimization etc.
 type ChangeReason int
 
@@ -33,3 +35,26 @@ type Pos struct {
 	X int
 	Y int
 }
+
+// CursorShape is the cursor shape selected by the child with DECSCUSR.
+type CursorShape int
+
+const (
+	// CursorBlock is a block cursor covering the whole cell.
+	CursorBlock CursorShape = iota
+
+	// CursorUnderline is an underline cursor at the bottom of the cell.
+	CursorUnderline
+
+	// CursorBar is a vertical bar cursor at the left of the cell.
+	CursorBar
+)
+
+// CursorState describes the cursor as the child wants it drawn.
+type CursorState struct {
+	Pos     Pos
+	Visible bool        // DECTCEM (CSI ? 25 h/l)
+	Shape   CursorShape // DECSCUSR (CSI Ps SP q)
+	Blink   bool        // DECSCUSR
+	Color   color.Color // OSC 12; nil means the host terminal's default
+}
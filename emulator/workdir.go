@@ -0,0 +1,71 @@
+package emulator
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// registerWorkingDirHandlers hooks the vt parser to forget the reported
+// working directory on reset.
+func (e *Emulator) registerWorkingDirHandlers() {
+	// RIS (ESC c) drops the directory reported with OSC 7, so WorkingDir
+	// falls back to /proc until the shell reports one again.
+	e.vt.RegisterEscHandler('c', func() bool {
+		e.workingDir = ""
+		return false
+	})
+}
+
+// setWorkingDir records the directory the child reported with OSC 7, given
+// as a file:// (or kitty-shell-cwd://) URL or a plain absolute path. Reports
+// from other hosts, such as a shell reached over ssh, are ignored since their
+// paths do not exist here. Must be called with mu held.
+func (e *Emulator) setWorkingDir(report string) {
+	if strings.HasPrefix(report, "/") {
+		e.workingDir = report
+		return
+	}
+	u, err := url.Parse(report)
+	if err != nil || (u.Scheme != "file" && u.Scheme != "kitty-shell-cwd") || u.Path == "" {
+		return
+	}
+	if !isLocalHost(u.Hostname()) {
+		return
+	}
+	e.workingDir = u.Path
+}
+
+// isLocalHost reports whether host, from an OSC 7 URL, names this machine.
+func isLocalHost(host string) bool {
+	if host == "" || host == "localhost" {
+		return true
+	}
+	name, err := os.Hostname()
+	return err == nil && strings.EqualFold(host, name)
+}
+
+// WorkingDir returns the current directory of the terminal: the one a shell
+// on this machine last reported with OSC 7 since the command started or the
+// terminal was reset or, if none did, the current directory of the PTY's
+// foreground process group leader read from /proc. It returns "" when neither
+// is known.
+func (e *Emulator) WorkingDir() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.workingDir != "" {
+		return e.workingDir
+	}
+	pgid, err := e.foregroundPgid()
+	if err != nil || pgid <= 0 {
+		if pgid, err = e.runningPid(); err != nil {
+			return ""
+		}
+	}
+	dir, err := os.Readlink("/proc/" + strconv.Itoa(pgid) + "/cwd")
+	if err != nil {
+		return ""
+	}
+	return dir
+}
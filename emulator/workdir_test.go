@@ -0,0 +1,83 @@
+package emulator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWorkingDirFromProc(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks failed: %v", err)
+	}
+	cmd := exec.Command("sleep", "10")
+	cmd.Dir = dir
+	e := startProcess(t, cmd)
+	defer e.Signal(syscall.SIGKILL)
+
+	if got := e.WorkingDir(); got != dir {
+		t.Fatalf("WorkingDir() = %q, want %q", got, dir)
+	}
+
+	// OSC 7 reports take precedence.
+	feed(e, "\x1b]7;file://localhost/tmp/a%20b\x07")
+	if got := e.WorkingDir(); got != "/tmp/a b" {
+		t.Fatalf("WorkingDir() = %q after OSC 7, want %q", got, "/tmp/a b")
+	}
+
+	// RIS forgets the report.
+	feed(e, "\x1bc")
+	if got := e.WorkingDir(); got != dir {
+		t.Fatalf("WorkingDir() = %q after RIS, want %q", got, dir)
+	}
+
+	// So does starting another command.
+	feed(e, "\x1b]7;file://localhost/tmp/a%20b\x07")
+	e.Signal(syscall.SIGKILL)
+	waitExit(t, e)
+	next, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks failed: %v", err)
+	}
+	cmd = exec.Command("sleep", "10")
+	cmd.Dir = next
+	if err := e.StartCommand(cmd); err != nil {
+		t.Fatalf("StartCommand failed: %v", err)
+	}
+	defer e.Signal(syscall.SIGKILL)
+	time.Sleep(100 * time.Millisecond)
+	if got := e.WorkingDir(); got != next {
+		t.Fatalf("WorkingDir() = %q for the new command, want %q", got, next)
+	}
+}
+
+func TestWorkingDirFromOSC7(t *testing.T) {
+	e := newDamageEmulator(t)
+	if got := e.WorkingDir(); got != "" {
+		t.Fatalf("WorkingDir() = %q without a process, want empty", got)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Fatalf("Hostname failed: %v", err)
+	}
+	for _, tt := range []struct {
+		report string
+		want   string
+	}{
+		{"file:///home/me", "/home/me"},
+		{"kitty-shell-cwd://" + hostname + "/srv", "/srv"},
+		{"/var/log", "/var/log"},
+		{"http://example.com/x", "/var/log"},          // ignored
+		{"file://remote.invalid/home/me", "/var/log"}, // another host, ignored
+	} {
+		feed(e, "\x1b]7;"+tt.report+"\x1b\\")
+		if got := e.WorkingDir(); got != tt.want {
+			t.Fatalf("after %q: WorkingDir() = %q, want %q", tt.report, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package bubbleterm
+
+import (
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/taigrr/bubbleterm/emulator"
+)
+
+// eventBufferSize is the number of undelivered events a Model queues before
+// dropping new notifications. Other events are never dropped.
+const eventBufferSize = 64
+
+// TitleChangedMsg is sent when the child changes the window title with OSC 0
+// or OSC 2, or restores one from the title stack.
+type TitleChangedMsg struct {
+	ID    string // Emulator ID
+	Title string
+}
+
+// ProcessExitedMsg is sent when the process started in the terminal exits.
+type ProcessExitedMsg struct {
+	ID       string         // Emulator ID
+	ExitCode int            // Exit code, or -1 if the process was killed by a signal
+	Signal   syscall.Signal // Signal that killed the process, or 0
+	Err      error          // Error from waiting on the process, other than a non-zero exit
+	Duration time.Duration  // Time from start to exit
+}
+
+// ForegroundChangedMsg is sent when the job in the terminal's foreground
+// changes, such as when the shell starts vim or vim exits back to the shell.
+// Compare Pid with the emulator's Pid to tell whether the shell itself is in
+// the foreground.
+type ForegroundChangedMsg struct {
+	ID        string // Emulator ID
+	Pid       int    // Leader of the foreground process group
+	Name      string // Command name
+	Args      []string
+	StartTime time.Time
+}
+
+// ClipboardMsg is sent when the child copies text with OSC 52. Hosts can
+// forward it to the real terminal with tea.SetClipboard, or
+// tea.SetPrimaryClipboard for ansi.PrimaryClipboard.
+type ClipboardMsg struct {
+	ID        string // Emulator ID
+	Selection byte   // OSC 52 selection name, such as ansi.SystemClipboard
+	Text      string // Copied text; empty when the child clears the selection
+}
+
+// ClipboardReadRequestMsg is sent when the child asks to read the clipboard
+// under emulator.ClipboardReadAsk. Answer it with the emulator's
+// AnswerClipboardRead.
+type ClipboardReadRequestMsg struct {
+	ID        string // Emulator ID
+	Selection byte   // OSC 52 selection name, such as ansi.SystemClipboard
+}
+
+// LinkClickedMsg is sent when an OSC 8 hyperlink is Ctrl+clicked, if enabled
+// with SetLinkClicks.
+type LinkClickedMsg struct {
+	ID     string // Emulator ID
+	URL    string
+	Params string // OSC 8 parameters, such as "id=1"
+	Text   string // Text of the clicked link on its row
+}
+
+// BellMsg is sent when the child rings the bell. Hosts can use it to flash or
+// badge a background pane.
+type BellMsg struct {
+	ID string // Emulator ID
+}
+
+// NotificationMsg is sent when the child requests a desktop notification with
+// OSC 9 or OSC 777.
+type NotificationMsg struct {
+	ID    string // Emulator ID
+	Title string // Empty for OSC 9, which only carries a body
+	Body  string
+}
+
+// ProgressMsg is sent when the progress the child reports with OSC 9;4
+// changes. Percent is 0 to 100.
+type ProgressMsg struct {
+	ID      string // Emulator ID
+	State   emulator.ProgressState
+	Percent int
+}
+
+// wireEvents routes the emulator callbacks into the model's event queue.
+// Replacing those callbacks on the emulator stops the matching messages.
+func (m *Model) wireEvents() {
+	m.events = newEventQueue()
+	m.emulator.SetOnTitleChange(func(id, title string) {
+		m.postEvent(TitleChangedMsg{ID: id, Title: title})
+	})
+	m.emulator.SetOnExitStatus(func(id string, status emulator.ExitStatus) {
+		m.postEvent(ProcessExitedMsg{
+			ID:       id,
+			ExitCode: status.ExitCode,
+			Signal:   status.Signal,
+			Err:      status.Err,
+			Duration: status.Duration,
+		})
+	})
+	m.emulator.SetOnForegroundChange(func(id string, p emulator.Process) {
+		m.postEvent(ForegroundChangedMsg{
+			ID:        id,
+			Pid:       p.Pid,
+			Name:      p.Name,
+			Args:      p.Args,
+			StartTime: p.StartTime,
+		})
+	})
+	m.emulator.SetOnClipboard(func(id string, selection byte, text string) {
+		m.postEvent(ClipboardMsg{ID: id, Selection: selection, Text: text})
+	})
+	m.emulator.SetOnClipboardRead(func(id string, selection byte) {
+		m.postEvent(ClipboardReadRequestMsg{ID: id, Selection: selection})
+	})
+	m.emulator.SetOnBell(func(id string) {
+		m.postEvent(BellMsg{ID: id})
+	})
+	m.emulator.SetOnNotification(func(id string, n emulator.Notification) {
+		m.postEvent(NotificationMsg{ID: id, Title: n.Title, Body: n.Body})
+	})
+	m.emulator.SetOnProgress(func(id string, state emulator.ProgressState, percent int) {
+		m.postEvent(ProgressMsg{ID: id, State: state, Percent: percent})
+	})
+}
+
+// postEvent queues msg for delivery without blocking the emulator's read
+// loop.
+func (m *Model) postEvent(msg tea.Msg) {
+	m.events.post(msg)
+}
+
+// poll returns the blocking auto-poll command, which also delivers events.
+func (m *Model) poll() tea.Cmd {
+	return pollTerminalEvents(m.emulator, m.events)
+}
+
+// drainEvents returns a command for each queued event without blocking. It is
+// used by the manual polling path, where no poll goroutine waits on events.
+func (m *Model) drainEvents() []tea.Cmd {
+	var cmds []tea.Cmd
+	for {
+		msg, ok := m.events.pop()
+		if !ok {
+			return cmds
+		}
+		cmds = append(cmds, func() tea.Msg { return msg })
+	}
+}
+
+// eventQueue holds the events posted by the emulator callbacks until the
+// model delivers them. Posting never blocks. Title, bell, progress and
+// foreground changes replace a queued event of the same type, since only the
+// latest one matters; notifications are dropped while eventBufferSize events
+// are queued; process exits and clipboard events are always kept.
+type eventQueue struct {
+	mu    sync.Mutex
+	msgs  []tea.Msg
+	ready chan struct{} // holds a signal while msgs is not empty
+}
+
+func newEventQueue() *eventQueue {
+	return &eventQueue{ready: make(chan struct{}, 1)}
+}
+
+// post queues msg.
+func (q *eventQueue) post(msg tea.Msg) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	switch msg.(type) {
+	case TitleChangedMsg, BellMsg, ProgressMsg, ForegroundChangedMsg:
+		for i, queued := range q.msgs {
+			if reflect.TypeOf(queued) == reflect.TypeOf(msg) {
+				q.msgs = append(q.msgs[:i], q.msgs[i+1:]...)
+				break
+			}
+		}
+	case NotificationMsg:
+		if len(q.msgs) >= eventBufferSize {
+			return
+		}
+	}
+	q.msgs = append(q.msgs, msg)
+	q.signal()
+}
+
+// pop removes and returns the oldest queued event.
+func (q *eventQueue) pop() (tea.Msg, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.msgs) == 0 {
+		return nil, false
+	}
+	msg := q.msgs[0]
+	q.msgs[0] = nil
+	q.msgs = q.msgs[1:]
+	if len(q.msgs) > 0 {
+		q.signal()
+	}
+	return msg, true
+}
+
+// signal wakes up a poll waiting on ready. Must be called with mu held.
+func (q *eventQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
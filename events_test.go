@@ -0,0 +1,251 @@
+package bubbleterm
+
+import (
+	"io"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/taigrr/bubbleterm/emulator"
+)
+
+// newEventModel returns a pipe-backed model with its initial frame consumed.
+func newEventModel(t *testing.T) (*Model, *io.PipeWriter) {
+	t.Helper()
+	pr, pw := io.Pipe()
+	ir, iw := io.Pipe()
+	go io.Copy(io.Discard, ir)
+
+	model, err := NewWithPipes(20, 3, pr, iw)
+	if err != nil {
+		t.Fatalf("NewWithPipes failed: %v", err)
+	}
+	t.Cleanup(func() { model.Close() })
+	model.GetEmulator().GetScreen()
+	return model, pw
+}
+
+// findMsg runs cmd, expanding batches, and returns the first message of type T.
+func findMsg[T tea.Msg](cmd tea.Cmd) (T, bool) {
+	var zero T
+	if cmd == nil {
+		return zero, false
+	}
+	switch msg := cmd().(type) {
+	case T:
+		return msg, true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if found, ok := findMsg[T](c); ok {
+				return found, true
+			}
+		}
+	}
+	return zero, false
+}
+
+// waitMsg polls the model manually until it delivers a message of type T.
+func waitMsg[T tea.Msg](t *testing.T, model *Model) T {
+	t.Helper()
+	model.SetAutoPoll(false)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if msg, ok := findMsg[T](model.UpdateTerminal()); ok {
+			return msg
+		}
+		if time.Now().After(deadline) {
+			var zero T
+			t.Fatalf("timed out waiting for %T", zero)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAutoPollDeliversTitleChangedMsg(t *testing.T) {
+	model, pw := newEventModel(t)
+
+	cmd := model.Init()
+	if _, err := pw.Write([]byte("\x1b]2;htop\x07")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+
+	var msg tea.Msg
+	for {
+		select {
+		case msg = <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the title event")
+		}
+		if _, ok := msg.(terminalEventMsg); ok {
+			break
+		}
+		// The title sequence may also damage the screen; keep polling.
+		_, cmd = model.Update(msg)
+		go func() { done <- cmd() }()
+	}
+
+	_, cmd = model.Update(msg)
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatal("expected the event to re-arm the poll alongside the message")
+	}
+	title, ok := batch[1]().(TitleChangedMsg)
+	if !ok {
+		t.Fatal("expected a TitleChangedMsg")
+	}
+	if title.ID != model.GetEmulator().ID() || title.Title != "htop" {
+		t.Fatalf("unexpected message %+v", title)
+	}
+}
+
+func TestUpdateTerminalDeliversTitleChangedMsg(t *testing.T) {
+	model, pw := newEventModel(t)
+	model.SetAutoPoll(false)
+
+	if _, err := pw.Write([]byte("\x1b]0;bash\x07")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if title, ok := findMsg[TitleChangedMsg](model.UpdateTerminal()); ok {
+			if title.Title != "bash" {
+				t.Fatalf("title %q, want bash", title.Title)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for TitleChangedMsg")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestProcessExitedMsg(t *testing.T) {
+	model, err := NewWithCommand(20, 3, exec.Command("sh", "-c", "exit 7"))
+	if err != nil {
+		t.Fatalf("NewWithCommand failed: %v", err)
+	}
+	defer model.Close()
+	model.SetAutoPoll(false)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if exited, ok := findMsg[ProcessExitedMsg](model.UpdateTerminal()); ok {
+			if exited.ID != model.GetEmulator().ID() || exited.ExitCode != 7 || exited.Signal != 0 {
+				t.Fatalf("unexpected message %+v", exited)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for ProcessExitedMsg")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestForegroundChangedMsg(t *testing.T) {
+	model, err := NewWithCommand(20, 3, exec.Command("sleep", "10"))
+	if err != nil {
+		t.Fatalf("NewWithCommand failed: %v", err)
+	}
+	defer model.Close()
+	defer model.GetEmulator().Signal(syscall.SIGKILL)
+
+	msg := waitMsg[ForegroundChangedMsg](t, model)
+	if msg.ID != model.GetEmulator().ID() || msg.Pid != model.GetEmulator().Pid() || msg.Name != "sleep" {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+}
+
+func TestClipboardMsg(t *testing.T) {
+	model, pw := newEventModel(t)
+
+	if _, err := pw.Write([]byte("\x1b]52;c;aGVsbG8=\x07")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	msg := waitMsg[ClipboardMsg](t, model)
+	if msg.ID != model.GetEmulator().ID() || msg.Selection != 'c' || msg.Text != "hello" {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+}
+
+func TestBellAndNotificationMsg(t *testing.T) {
+	model, pw := newEventModel(t)
+
+	if _, err := pw.Write([]byte("\a")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	if msg := waitMsg[BellMsg](t, model); msg.ID != model.GetEmulator().ID() {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+
+	if _, err := pw.Write([]byte("\x1b]777;notify;make;done\x1b\\")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	msg := waitMsg[NotificationMsg](t, model)
+	if msg.ID != model.GetEmulator().ID() || msg.Title != "make" || msg.Body != "done" {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+}
+
+func TestProgressMsg(t *testing.T) {
+	model, pw := newEventModel(t)
+
+	if _, err := pw.Write([]byte("\x1b]9;4;1;25\x07")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	msg := waitMsg[ProgressMsg](t, model)
+	if msg.ID != model.GetEmulator().ID() || msg.State != emulator.ProgressNormal || msg.Percent != 25 {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+}
+
+func TestEventQueueKeepsExitAndClipboardEvents(t *testing.T) {
+	q := newEventQueue()
+	q.post(ClipboardMsg{ID: "t", Text: "copied"})
+	for i := range 2 * eventBufferSize {
+		q.post(TitleChangedMsg{ID: "t", Title: strconv.Itoa(i)})
+		q.post(BellMsg{ID: "t"})
+		q.post(ProgressMsg{ID: "t", Percent: i})
+		q.post(NotificationMsg{ID: "t", Body: strconv.Itoa(i)})
+	}
+	q.post(ProcessExitedMsg{ID: "t", ExitCode: 1})
+
+	var clipboards, exits, titles, bells, notifications int
+	var title TitleChangedMsg
+	for {
+		msg, ok := q.pop()
+		if !ok {
+			break
+		}
+		switch msg := msg.(type) {
+		case ClipboardMsg:
+			clipboards++
+		case ProcessExitedMsg:
+			exits++
+		case TitleChangedMsg:
+			titles++
+			title = msg
+		case BellMsg:
+			bells++
+		case NotificationMsg:
+			notifications++
+		}
+	}
+	if clipboards != 1 || exits != 1 {
+		t.Fatalf("got %d clipboard and %d exit events, want 1 each", clipboards, exits)
+	}
+	if titles != 1 || bells != 1 || title.Title != strconv.Itoa(2*eventBufferSize-1) {
+		t.Fatalf("got %d titles (last %q) and %d bells, want only the latest of each", titles, title.Title, bells)
+	}
+	if notifications == 0 || notifications > eventBufferSize {
+		t.Fatalf("got %d notifications, want 1 to %d", notifications, eventBufferSize)
+	}
+}
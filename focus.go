@@ -0,0 +1,19 @@
+package bubbleterm
+
+import tea "charm.land/bubbletea/v2"
+
+// setFocus updates the model's focus and the host terminal's focus. The child
+// sees focus only while both are set; whenever that changes, the returned
+// command sends it a focus report (if it enabled mode 1004).
+func (m *Model) setFocus(focused, hostFocused bool) tea.Cmd {
+	was := m.focused && m.hostFocused
+	m.focused = focused
+	m.hostFocused = hostFocused
+	if m.inlineCursor {
+		m.renderView()
+	}
+	if is := focused && hostFocused; is != was {
+		return sendFocus(m.emulator, is)
+	}
+	return nil
+}
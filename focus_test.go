@@ -0,0 +1,99 @@
+package bubbleterm
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// writeMode writes s as the child's output and waits until set reports the
+// mode it enables.
+func writeMode(t *testing.T, pw *io.PipeWriter, s string, set func() bool) {
+	t.Helper()
+	if _, err := pw.Write([]byte(s)); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !set() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %q to be processed", s)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// expectInput fails unless the child receives want next.
+func expectInput(t *testing.T, input <-chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-input:
+		if got != want {
+			t.Fatalf("child received %q, want %q", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %q", want)
+	}
+}
+
+// expectNoInput fails if the child receives anything shortly.
+func expectNoInput(t *testing.T, input <-chan string) {
+	t.Helper()
+	select {
+	case got := <-input:
+		t.Fatalf("unexpected input %q", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// runCmd runs cmd, if any, as the bubbletea runtime would.
+func runCmd(cmd tea.Cmd) {
+	if cmd != nil {
+		cmd()
+	}
+}
+
+// updateCmd passes msg to the model and returns the command it produced.
+func updateCmd(model *Model, msg tea.Msg) tea.Cmd {
+	_, cmd := model.Update(msg)
+	return cmd
+}
+
+func TestFocusReporting(t *testing.T) {
+	model, pw, input := newInputModel(t)
+
+	// Without mode 1004 nothing is reported.
+	runCmd(model.Blur())
+	runCmd(model.Focus())
+	expectNoInput(t, input)
+
+	writeMode(t, pw, "\x1b[?1004h", model.GetEmulator().IsFocusReporting)
+	// The report is written by the returned command, not by Blur itself.
+	cmd := model.Blur()
+	expectNoInput(t, input)
+	runCmd(cmd)
+	expectInput(t, input, "\x1b[O")
+
+	// Repeated blurs are not reported again.
+	runCmd(model.Blur())
+	expectNoInput(t, input)
+	runCmd(model.Focus())
+	expectInput(t, input, "\x1b[I")
+
+	// The host terminal losing focus is reported while the model is focused.
+	runCmd(updateCmd(model, tea.BlurMsg{}))
+	expectInput(t, input, "\x1b[O")
+	if !model.Focused() {
+		t.Fatal("host blur should not unfocus the model")
+	}
+	runCmd(updateCmd(model, tea.FocusMsg{}))
+	expectInput(t, input, "\x1b[I")
+
+	// While the model is blurred, host focus changes are not reported.
+	runCmd(model.Blur())
+	expectInput(t, input, "\x1b[O")
+	runCmd(updateCmd(model, tea.BlurMsg{}))
+	runCmd(updateCmd(model, tea.FocusMsg{}))
+	expectNoInput(t, input)
+}
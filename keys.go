@@ -4,12 +4,45 @@ import (
 	"strconv"
 
 	tea "charm.land/bubbletea/v2"
+	"github.com/taigrr/bubbleterm/emulator"
 )
 
-// keyToTerminalInput converts bubbletea key messages to terminal input byte sequences.
-// It uses structured key fields (Code, Mod, Text) instead of string matching
-// to handle all key combinations programmatically.
+// keyModes holds the terminal modes that change how keys are encoded.
+type keyModes struct {
+	appCursor bool // DECCKM: unmodified cursor keys send SS3 instead of CSI
+	appKeypad bool // DECKPAM: keypad keys send SS3 application sequences
+	kitty     int  // Kitty keyboard protocol flags; 0 selects legacy encoding
+}
+
+// terminalKeyModes returns the key modes the child has currently set.
+func terminalKeyModes(emu *emulator.Emulator) keyModes {
+	return keyModes{
+		appCursor: emu.IsApplicationCursorKeys(),
+		appKeypad: emu.IsApplicationKeypad(),
+		kitty:     emu.KittyKeyboardFlags(),
+	}
+}
+
+// keyToTerminalInput converts bubbletea key messages to terminal input byte
+// sequences for a terminal in the default (normal cursor and numeric keypad,
+// legacy keyboard) modes.
 func keyToTerminalInput(msg tea.KeyMsg) string {
+	return encodeKey(msg, keyModes{})
+}
+
+// encodeKey converts bubbletea key messages to terminal input byte sequences.
+// It uses structured key fields (Code, Mod, Text) instead of string matching
+// to handle all key combinations programmatically. Children that enabled the
+// kitty keyboard protocol get its encoding; otherwise legacy xterm sequences
+// are sent and key releases are dropped.
+func encodeKey(msg tea.KeyMsg, modes keyModes) string {
+	if modes.kitty != 0 {
+		return encodeKittyKey(msg, modes)
+	}
+	if _, ok := msg.(tea.KeyReleaseMsg); ok {
+		return ""
+	}
+
 	k := msg.Key()
 	mod := k.Mod & (tea.ModShift | tea.ModAlt | tea.ModCtrl)
 
@@ -48,25 +81,27 @@ func keyToTerminalInput(msg tea.KeyMsg) string {
 			return "\x1b "
 		}
 		return " "
-	case tea.KeyUp:
-		return csiLetter('A', mod)
-	case tea.KeyDown:
-		return csiLetter('B', mod)
-	case tea.KeyRight:
-		return csiLetter('C', mod)
-	case tea.KeyLeft:
-		return csiLetter('D', mod)
-	case tea.KeyHome:
-		return csiLetter('H', mod)
-	case tea.KeyEnd:
-		return csiLetter('F', mod)
-	case tea.KeyInsert:
+	case tea.KeyUp, tea.KeyKpUp:
+		return cursorKey('A', mod, modes)
+	case tea.KeyDown, tea.KeyKpDown:
+		return cursorKey('B', mod, modes)
+	case tea.KeyRight, tea.KeyKpRight:
+		return cursorKey('C', mod, modes)
+	case tea.KeyLeft, tea.KeyKpLeft:
+		return cursorKey('D', mod, modes)
+	case tea.KeyBegin, tea.KeyKpBegin:
+		return cursorKey('E', mod, modes)
+	case tea.KeyHome, tea.KeyKpHome:
+		return cursorKey('H', mod, modes)
+	case tea.KeyEnd, tea.KeyKpEnd:
+		return cursorKey('F', mod, modes)
+	case tea.KeyInsert, tea.KeyKpInsert:
 		return csiTilde(2, mod)
-	case tea.KeyDelete:
+	case tea.KeyDelete, tea.KeyKpDelete:
 		return csiTilde(3, mod)
-	case tea.KeyPgUp:
+	case tea.KeyPgUp, tea.KeyKpPgUp:
 		return csiTilde(5, mod)
-	case tea.KeyPgDown:
+	case tea.KeyPgDown, tea.KeyKpPgDown:
 		return csiTilde(6, mod)
 	case tea.KeyF1:
 		return ss3Func('P', mod)
@@ -94,6 +129,10 @@ func keyToTerminalInput(msg tea.KeyMsg) string {
 		return csiTilde(24, mod)
 	}
 
+	if seq, ok := keypadKey(k.Code, mod, modes); ok {
+		return seq
+	}
+
 	// Printable text (covers regular characters, shift+letter producing uppercase, symbols)
 	if k.Text != "" {
 		if mod&tea.ModAlt != 0 {
@@ -179,6 +218,59 @@ func csiLetter(final byte, mod tea.KeyMod) string {
 	return "\x1b[1;" + strconv.Itoa(modParam(mod)) + string(final)
 }
 
+// cursorKey returns the sequence for arrow/begin/home/end keys. Unmodified keys
+// send SS3 (\x1bOX) instead of CSI in application cursor keys mode (DECCKM).
+func cursorKey(final byte, mod tea.KeyMod, modes keyModes) string {
+	if mod == 0 && modes.appCursor {
+		return "\x1bO" + string(final)
+	}
+	return csiLetter(final, mod)
+}
+
+// keypadKeys maps the keypad's character keys to the character they type in
+// numeric keypad mode and the SS3 final byte they send in application keypad
+// mode (DECKPAM).
+var keypadKeys = map[rune]struct {
+	char  string
+	final byte
+}{
+	tea.KeyKp0:        {"0", 'p'},
+	tea.KeyKp1:        {"1", 'q'},
+	tea.KeyKp2:        {"2", 'r'},
+	tea.KeyKp3:        {"3", 's'},
+	tea.KeyKp4:        {"4", 't'},
+	tea.KeyKp5:        {"5", 'u'},
+	tea.KeyKp6:        {"6", 'v'},
+	tea.KeyKp7:        {"7", 'w'},
+	tea.KeyKp8:        {"8", 'x'},
+	tea.KeyKp9:        {"9", 'y'},
+	tea.KeyKpEnter:    {"\r", 'M'},
+	tea.KeyKpEqual:    {"=", 'X'},
+	tea.KeyKpMultiply: {"*", 'j'},
+	tea.KeyKpPlus:     {"+", 'k'},
+	tea.KeyKpComma:    {",", 'l'},
+	tea.KeyKpMinus:    {"-", 'm'},
+	tea.KeyKpDecimal:  {".", 'n'},
+	tea.KeyKpDivide:   {"/", 'o'},
+}
+
+// keypadKey returns the sequence for a keypad character key: the character in
+// numeric keypad mode, and SS3 sequences like ss3Func's in application keypad
+// mode.
+func keypadKey(code rune, mod tea.KeyMod, modes keyModes) (string, bool) {
+	key, ok := keypadKeys[code]
+	if !ok {
+		return "", false
+	}
+	if !modes.appKeypad {
+		if mod&tea.ModAlt != 0 {
+			return "\x1b" + key.char, true
+		}
+		return key.char, true
+	}
+	return ss3Func(key.final, mod), true
+}
+
 // csiTilde returns CSI tilde sequences for insert/delete/pgup/pgdown/function keys.
 // Plain: \x1b[N~, Modified: \x1b[N;{param}~
 func csiTilde(n int, mod tea.KeyMod) string {
@@ -189,7 +281,7 @@ func csiTilde(n int, mod tea.KeyMod) string {
 	return "\x1b[" + ns + ";" + strconv.Itoa(modParam(mod)) + "~"
 }
 
-// ss3Func returns SS3 sequences for F1-F4.
+// ss3Func returns SS3 sequences for F1-F4 and application keypad keys.
 // Plain: \x1bOX, Modified: \x1b[1;{param}X
 func ss3Func(final byte, mod tea.KeyMod) string {
 	if mod == 0 {
@@ -0,0 +1,270 @@
+package bubbleterm
+
+import (
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// kittyKeyKind classifies the functional keys of the kitty keyboard protocol.
+type kittyKeyKind int
+
+const (
+	kittyKeyFunctional kittyKeyKind = iota
+	kittyKeyLegacy                  // Enter, Tab and Backspace keep legacy bytes unless all keys are reported
+	kittyKeyKeypad                  // Keypad keys, which type their Text when it is set
+	kittyKeyModifier                // Modifier and lock keys, only reported with all keys
+)
+
+// kittyKey is the encoding of a functional key: CSI number ; mods final.
+type kittyKey struct {
+	number int
+	final  byte
+	kind   kittyKeyKind
+}
+
+// kittyKeys maps bubbletea key codes to their kitty keyboard protocol
+// encoding. Keys missing from the map are text keys, encoded by code point.
+var kittyKeys = map[rune]kittyKey{
+	tea.KeyEscape:    {27, 'u', kittyKeyFunctional},
+	tea.KeyEnter:     {13, 'u', kittyKeyLegacy},
+	tea.KeyTab:       {9, 'u', kittyKeyLegacy},
+	tea.KeyBackspace: {127, 'u', kittyKeyLegacy},
+	tea.KeyInsert:    {2, '~', kittyKeyFunctional},
+	tea.KeyDelete:    {3, '~', kittyKeyFunctional},
+	tea.KeyLeft:      {1, 'D', kittyKeyFunctional},
+	tea.KeyRight:     {1, 'C', kittyKeyFunctional},
+	tea.KeyUp:        {1, 'A', kittyKeyFunctional},
+	tea.KeyDown:      {1, 'B', kittyKeyFunctional},
+	tea.KeyPgUp:      {5, '~', kittyKeyFunctional},
+	tea.KeyPgDown:    {6, '~', kittyKeyFunctional},
+	tea.KeyHome:      {1, 'H', kittyKeyFunctional},
+	tea.KeyEnd:       {1, 'F', kittyKeyFunctional},
+	tea.KeyBegin:     {1, 'E', kittyKeyFunctional},
+	tea.KeyF1:        {1, 'P', kittyKeyFunctional},
+	tea.KeyF2:        {1, 'Q', kittyKeyFunctional},
+	tea.KeyF3:        {13, '~', kittyKeyFunctional},
+	tea.KeyF4:        {1, 'S', kittyKeyFunctional},
+	tea.KeyF5:        {15, '~', kittyKeyFunctional},
+	tea.KeyF6:        {17, '~', kittyKeyFunctional},
+	tea.KeyF7:        {18, '~', kittyKeyFunctional},
+	tea.KeyF8:        {19, '~', kittyKeyFunctional},
+	tea.KeyF9:        {20, '~', kittyKeyFunctional},
+	tea.KeyF10:       {21, '~', kittyKeyFunctional},
+	tea.KeyF11:       {23, '~', kittyKeyFunctional},
+	tea.KeyF12:       {24, '~', kittyKeyFunctional},
+
+	tea.KeyCapsLock:    {57358, 'u', kittyKeyModifier},
+	tea.KeyScrollLock:  {57359, 'u', kittyKeyModifier},
+	tea.KeyNumLock:     {57360, 'u', kittyKeyModifier},
+	tea.KeyPrintScreen: {57361, 'u', kittyKeyFunctional},
+	tea.KeyPause:       {57362, 'u', kittyKeyFunctional},
+	tea.KeyMenu:        {57363, 'u', kittyKeyFunctional},
+
+	tea.KeyKp0:        {57399, 'u', kittyKeyKeypad},
+	tea.KeyKp1:        {57400, 'u', kittyKeyKeypad},
+	tea.KeyKp2:        {57401, 'u', kittyKeyKeypad},
+	tea.KeyKp3:        {57402, 'u', kittyKeyKeypad},
+	tea.KeyKp4:        {57403, 'u', kittyKeyKeypad},
+	tea.KeyKp5:        {57404, 'u', kittyKeyKeypad},
+	tea.KeyKp6:        {57405, 'u', kittyKeyKeypad},
+	tea.KeyKp7:        {57406, 'u', kittyKeyKeypad},
+	tea.KeyKp8:        {57407, 'u', kittyKeyKeypad},
+	tea.KeyKp9:        {57408, 'u', kittyKeyKeypad},
+	tea.KeyKpDecimal:  {57409, 'u', kittyKeyKeypad},
+	tea.KeyKpDivide:   {57410, 'u', kittyKeyKeypad},
+	tea.KeyKpMultiply: {57411, 'u', kittyKeyKeypad},
+	tea.KeyKpMinus:    {57412, 'u', kittyKeyKeypad},
+	tea.KeyKpPlus:     {57413, 'u', kittyKeyKeypad},
+	tea.KeyKpEnter:    {57414, 'u', kittyKeyKeypad},
+	tea.KeyKpEqual:    {57415, 'u', kittyKeyKeypad},
+	tea.KeyKpSep:      {57416, 'u', kittyKeyKeypad},
+	tea.KeyKpLeft:     {57417, 'u', kittyKeyKeypad},
+	tea.KeyKpRight:    {57418, 'u', kittyKeyKeypad},
+	tea.KeyKpUp:       {57419, 'u', kittyKeyKeypad},
+	tea.KeyKpDown:     {57420, 'u', kittyKeyKeypad},
+	tea.KeyKpPgUp:     {57421, 'u', kittyKeyKeypad},
+	tea.KeyKpPgDown:   {57422, 'u', kittyKeyKeypad},
+	tea.KeyKpHome:     {57423, 'u', kittyKeyKeypad},
+	tea.KeyKpEnd:      {57424, 'u', kittyKeyKeypad},
+	tea.KeyKpInsert:   {57425, 'u', kittyKeyKeypad},
+	tea.KeyKpDelete:   {57426, 'u', kittyKeyKeypad},
+	tea.KeyKpBegin:    {57427, 'u', kittyKeyKeypad},
+
+	tea.KeyLeftShift:      {57441, 'u', kittyKeyModifier},
+	tea.KeyLeftCtrl:       {57442, 'u', kittyKeyModifier},
+	tea.KeyLeftAlt:        {57443, 'u', kittyKeyModifier},
+	tea.KeyLeftSuper:      {57444, 'u', kittyKeyModifier},
+	tea.KeyLeftHyper:      {57445, 'u', kittyKeyModifier},
+	tea.KeyLeftMeta:       {57446, 'u', kittyKeyModifier},
+	tea.KeyRightShift:     {57447, 'u', kittyKeyModifier},
+	tea.KeyRightCtrl:      {57448, 'u', kittyKeyModifier},
+	tea.KeyRightAlt:       {57449, 'u', kittyKeyModifier},
+	tea.KeyRightSuper:     {57450, 'u', kittyKeyModifier},
+	tea.KeyRightHyper:     {57451, 'u', kittyKeyModifier},
+	tea.KeyRightMeta:      {57452, 'u', kittyKeyModifier},
+	tea.KeyIsoLevel3Shift: {57453, 'u', kittyKeyModifier},
+	tea.KeyIsoLevel5Shift: {57454, 'u', kittyKeyModifier},
+}
+
+func init() {
+	// F13-F35 and the media keys are numbered consecutively in both
+	// bubbletea and the kitty protocol.
+	for i := range rune(23) {
+		kittyKeys[tea.KeyF13+i] = kittyKey{57376 + int(i), 'u', kittyKeyFunctional}
+	}
+	for i := range rune(13) {
+		kittyKeys[tea.KeyMediaPlay+i] = kittyKey{57428 + int(i), 'u', kittyKeyFunctional}
+	}
+}
+
+// kittyModifiers maps bubbletea modifiers to kitty modifier bits.
+var kittyModifiers = []struct {
+	mod tea.KeyMod
+	bit int
+}{
+	{tea.ModShift, 1},
+	{tea.ModAlt, 2},
+	{tea.ModCtrl, 4},
+	{tea.ModSuper, 8},
+	{tea.ModHyper, 16},
+	{tea.ModMeta, 32},
+	{tea.ModCapsLock, 64},
+	{tea.ModNumLock, 128},
+}
+
+// textModifiers are the modifiers that keep a text key typing its text.
+const textModifiers = tea.ModShift | tea.ModCapsLock | tea.ModNumLock
+
+// kittyMods returns the kitty modifier bits for mod. Lock modifiers are only
+// reported when all keys are reported as escape codes.
+func kittyMods(mod tea.KeyMod, withLocks bool) int {
+	if !withLocks {
+		mod &^= tea.ModCapsLock | tea.ModNumLock
+	}
+	bits := 0
+	for _, m := range kittyModifiers {
+		if mod&m.mod != 0 {
+			bits |= m.bit
+		}
+	}
+	return bits
+}
+
+// Kitty key event types.
+const (
+	kittyPress   = 1
+	kittyRepeat  = 2
+	kittyRelease = 3
+)
+
+// encodeKittyKey converts a key event to input for a child that enabled the
+// kitty keyboard protocol with modes.kitty (a combination of ansi.Kitty*
+// flags). It returns "" for events the flags do not ask for, such as releases
+// without ansi.KittyReportEventTypes.
+func encodeKittyKey(msg tea.KeyMsg, modes keyModes) string {
+	k := msg.Key()
+	flags := modes.kitty
+	all := flags&ansi.KittyReportAllKeysAsEscapeCodes != 0
+
+	event := kittyPress
+	if _, ok := msg.(tea.KeyReleaseMsg); ok {
+		event = kittyRelease
+	} else if k.IsRepeat {
+		event = kittyRepeat
+	}
+	if flags&ansi.KittyReportEventTypes == 0 {
+		if event == kittyRelease {
+			return ""
+		}
+		event = kittyPress
+	}
+
+	mods := kittyMods(k.Mod, all)
+	plain := k.Mod&^textModifiers == 0
+
+	if key, ok := kittyKeys[k.Code]; ok {
+		switch key.kind {
+		case kittyKeyModifier:
+			if !all {
+				return ""
+			}
+		case kittyKeyLegacy:
+			if !all && mods == 0 {
+				if event == kittyRelease {
+					return ""
+				}
+				return string(k.Code)
+			}
+		case kittyKeyKeypad:
+			if !all && plain && k.Text != "" && event != kittyRelease {
+				return k.Text
+			}
+		case kittyKeyFunctional:
+			// Unmodified presses of keys with legacy CSI/SS3 forms (arrows,
+			// F1-F4) keep them, honoring application cursor keys mode.
+			if !all && mods == 0 && event == kittyPress && key.final != '~' && key.final != 'u' {
+				modes.kitty = 0
+				return encodeKey(msg, modes)
+			}
+		}
+		return kittyCSI(strconv.Itoa(key.number), key.final, mods, event, "")
+	}
+	if k.Code >= tea.KeyExtended || k.Code < 0x20 || k.Code == 0x7f {
+		return ""
+	}
+
+	// Text keys type their text unless modified or all keys are reported.
+	text := k.Text
+	if text == "" && plain {
+		text = string(k.Code)
+	}
+	if !all && plain && event != kittyRelease {
+		return text
+	}
+
+	code := strconv.Itoa(int(k.Code))
+	if flags&ansi.KittyReportAlternateKeys != 0 {
+		var shifted, base string
+		if k.Mod&tea.ModShift != 0 && k.ShiftedCode != 0 && k.ShiftedCode != k.Code {
+			shifted = strconv.Itoa(int(k.ShiftedCode))
+		}
+		if k.BaseCode != 0 && k.BaseCode != k.Code {
+			base = ":" + strconv.Itoa(int(k.BaseCode))
+		}
+		if shifted != "" || base != "" {
+			code += ":" + shifted + base
+		}
+	}
+
+	var codepoints string
+	if all && flags&ansi.KittyReportAssociatedKeys != 0 && event != kittyRelease && k.Text != "" {
+		cps := make([]string, 0, len(k.Text))
+		for _, r := range k.Text {
+			cps = append(cps, strconv.Itoa(int(r)))
+		}
+		codepoints = strings.Join(cps, ":")
+	}
+	return kittyCSI(code, 'u', mods, event, codepoints)
+}
+
+// kittyCSI formats CSI number ; mods:event ; text final, leaving out trailing
+// defaults. A number of 1 is left out too for keys with letter finals.
+func kittyCSI(number string, final byte, mods, event int, text string) string {
+	var params string
+	if mods != 0 || event != kittyPress || text != "" {
+		params = ";" + strconv.Itoa(mods+1)
+		if event != kittyPress {
+			params += ":" + strconv.Itoa(event)
+		}
+	}
+	if text != "" {
+		params += ";" + text
+	}
+	if number == "1" && params == "" && final != '~' && final != 'u' {
+		number = ""
+	}
+	return "\x1b[" + number + params + string(final)
+}
@@ -0,0 +1,110 @@
+package bubbleterm
+
+import (
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestEncodeKittyKey(t *testing.T) {
+	const (
+		disambiguate = ansi.KittyDisambiguateEscapeCodes
+		events       = disambiguate | ansi.KittyReportEventTypes
+		alternates   = disambiguate | ansi.KittyReportAlternateKeys
+		all          = disambiguate | ansi.KittyReportAllKeysAsEscapeCodes
+		allText      = all | ansi.KittyReportAssociatedKeys
+	)
+	tests := []struct {
+		name     string
+		msg      tea.KeyMsg
+		modes    keyModes
+		expected string
+	}{
+		// Disambiguation keeps text and legacy Enter/Tab/Backspace
+		{"text", tea.KeyPressMsg{Code: 'a', Text: "a"}, keyModes{kitty: disambiguate}, "a"},
+		{"shift text", tea.KeyPressMsg{Code: 'a', Text: "A", Mod: tea.ModShift}, keyModes{kitty: disambiguate}, "A"},
+		{"enter", tea.KeyPressMsg{Code: tea.KeyEnter}, keyModes{kitty: disambiguate}, "\r"},
+		{"tab", tea.KeyPressMsg{Code: tea.KeyTab}, keyModes{kitty: disambiguate}, "\t"},
+		{"backspace", tea.KeyPressMsg{Code: tea.KeyBackspace}, keyModes{kitty: disambiguate}, "\x7f"},
+		{"escape", tea.KeyPressMsg{Code: tea.KeyEscape}, keyModes{kitty: disambiguate}, "\x1b[27u"},
+		{"ctrl+i", tea.KeyPressMsg{Code: 'i', Mod: tea.ModCtrl}, keyModes{kitty: disambiguate}, "\x1b[105;5u"},
+		{"ctrl+c", tea.KeyPressMsg{Code: 'c', Mod: tea.ModCtrl}, keyModes{kitty: disambiguate}, "\x1b[99;5u"},
+		{"alt+a", tea.KeyPressMsg{Code: 'a', Text: "a", Mod: tea.ModAlt}, keyModes{kitty: disambiguate}, "\x1b[97;3u"},
+		{"shift+enter", tea.KeyPressMsg{Code: tea.KeyEnter, Mod: tea.ModShift}, keyModes{kitty: disambiguate}, "\x1b[13;2u"},
+		{"ctrl+tab", tea.KeyPressMsg{Code: tea.KeyTab, Mod: tea.ModCtrl}, keyModes{kitty: disambiguate}, "\x1b[9;5u"},
+
+		// Super, Hyper and Meta are encoded instead of masked off
+		{"super+a", tea.KeyPressMsg{Code: 'a', Text: "a", Mod: tea.ModSuper}, keyModes{kitty: disambiguate}, "\x1b[97;9u"},
+		{"hyper+a", tea.KeyPressMsg{Code: 'a', Mod: tea.ModHyper}, keyModes{kitty: disambiguate}, "\x1b[97;17u"},
+		{"meta+a", tea.KeyPressMsg{Code: 'a', Mod: tea.ModMeta}, keyModes{kitty: disambiguate}, "\x1b[97;33u"},
+		{"ctrl+super+up", tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModCtrl | tea.ModSuper}, keyModes{kitty: disambiguate}, "\x1b[1;13A"},
+		{"caps lock text", tea.KeyPressMsg{Code: 'a', Text: "A", Mod: tea.ModCapsLock}, keyModes{kitty: disambiguate}, "A"},
+
+		// Functional keys
+		{"up", tea.KeyPressMsg{Code: tea.KeyUp}, keyModes{kitty: disambiguate}, "\x1b[A"},
+		{"up app cursor", tea.KeyPressMsg{Code: tea.KeyUp}, keyModes{kitty: disambiguate, appCursor: true}, "\x1bOA"},
+		{"f1", tea.KeyPressMsg{Code: tea.KeyF1}, keyModes{kitty: disambiguate}, "\x1bOP"},
+		{"f3", tea.KeyPressMsg{Code: tea.KeyF3}, keyModes{kitty: disambiguate}, "\x1b[13~"},
+		{"shift+f3", tea.KeyPressMsg{Code: tea.KeyF3, Mod: tea.ModShift}, keyModes{kitty: disambiguate}, "\x1b[13;2~"},
+		{"pgup", tea.KeyPressMsg{Code: tea.KeyPgUp}, keyModes{kitty: disambiguate}, "\x1b[5~"},
+		{"f13", tea.KeyPressMsg{Code: tea.KeyF13}, keyModes{kitty: disambiguate}, "\x1b[57376u"},
+		{"mute", tea.KeyPressMsg{Code: tea.KeyMute}, keyModes{kitty: disambiguate}, "\x1b[57440u"},
+		{"kp 1 text", tea.KeyPressMsg{Code: tea.KeyKp1, Text: "1"}, keyModes{kitty: disambiguate}, "1"},
+		{"kp up", tea.KeyPressMsg{Code: tea.KeyKpUp}, keyModes{kitty: disambiguate}, "\x1b[57419u"},
+		{"left shift", tea.KeyPressMsg{Code: tea.KeyLeftShift, Mod: tea.ModShift}, keyModes{kitty: disambiguate}, ""},
+
+		// Event types
+		{"release dropped", tea.KeyReleaseMsg{Code: 'a', Text: "a"}, keyModes{kitty: disambiguate}, ""},
+		{"repeat as press", tea.KeyPressMsg{Code: 'a', Text: "a", IsRepeat: true}, keyModes{kitty: disambiguate}, "a"},
+		{"text release", tea.KeyReleaseMsg{Code: 'a'}, keyModes{kitty: events}, "\x1b[97;1:3u"},
+		{"text repeat", tea.KeyPressMsg{Code: 'a', Text: "a", IsRepeat: true}, keyModes{kitty: events}, "a"},
+		{"up release", tea.KeyReleaseMsg{Code: tea.KeyUp}, keyModes{kitty: events}, "\x1b[1;1:3A"},
+		{"ctrl+up repeat", tea.KeyPressMsg{Code: tea.KeyUp, Mod: tea.ModCtrl, IsRepeat: true}, keyModes{kitty: events}, "\x1b[1;5:2A"},
+		{"enter release", tea.KeyReleaseMsg{Code: tea.KeyEnter}, keyModes{kitty: events}, ""},
+		{"escape release", tea.KeyReleaseMsg{Code: tea.KeyEscape}, keyModes{kitty: events}, "\x1b[27;1:3u"},
+
+		// Alternate keys
+		{"shifted", tea.KeyPressMsg{Code: 'a', ShiftedCode: 'A', Mod: tea.ModShift | tea.ModCtrl}, keyModes{kitty: alternates}, "\x1b[97:65;6u"},
+		{"base layout", tea.KeyPressMsg{Code: 0x444, BaseCode: 'c', Mod: tea.ModCtrl}, keyModes{kitty: alternates}, "\x1b[1092::99;5u"},
+
+		// All keys as escape codes
+		{"all text", tea.KeyPressMsg{Code: 'a', Text: "a"}, keyModes{kitty: all}, "\x1b[97u"},
+		{"all enter", tea.KeyPressMsg{Code: tea.KeyEnter}, keyModes{kitty: all}, "\x1b[13u"},
+		{"all caps lock", tea.KeyPressMsg{Code: 'a', Text: "A", Mod: tea.ModCapsLock}, keyModes{kitty: all}, "\x1b[97;65u"},
+		{"all left shift", tea.KeyPressMsg{Code: tea.KeyLeftShift, Mod: tea.ModShift}, keyModes{kitty: all}, "\x1b[57441;2u"},
+		{"all kp 1", tea.KeyPressMsg{Code: tea.KeyKp1, Text: "1"}, keyModes{kitty: all}, "\x1b[57400u"},
+		{"associated text", tea.KeyPressMsg{Code: 'a', Text: "A", Mod: tea.ModShift}, keyModes{kitty: allText}, "\x1b[97;2;65u"},
+		{"associated text plain", tea.KeyPressMsg{Code: 'a', Text: "a"}, keyModes{kitty: allText}, "\x1b[97;1;97u"},
+
+		// Legacy encoding drops releases
+		{"legacy release", tea.KeyReleaseMsg{Code: 'a', Text: "a"}, keyModes{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := encodeKey(tt.msg, tt.modes)
+			if got != tt.expected {
+				t.Errorf("encodeKey(%q, %+v) = %q, want %q", tt.msg.String(), tt.modes, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestModelUpdateKeyMsgUsesKittyProtocol(t *testing.T) {
+	model, pw, input := newInputModel(t)
+	emu := model.GetEmulator()
+
+	writeMode(t, pw, "\x1b[>1u", func() bool { return emu.KittyKeyboardFlags() == 1 })
+	_, cmd := model.Update(tea.KeyPressMsg{Code: 'i', Mod: tea.ModCtrl})
+	if cmd == nil {
+		t.Fatal("expected command for ctrl+i")
+	}
+	cmd()
+	expectInput(t, input, "\x1b[105;5u")
+
+	writeMode(t, pw, "\x1b[<u", func() bool { return emu.KittyKeyboardFlags() == 0 })
+	_, cmd = model.Update(tea.KeyPressMsg{Code: 'i', Mod: tea.ModCtrl})
+	cmd()
+	expectInput(t, input, "\t")
+}
@@ -0,0 +1,43 @@
+package bubbleterm
+
+import tea "charm.land/bubbletea/v2"
+
+// SetLinkClicks controls Ctrl+click on hyperlinks. When enabled, Ctrl+left
+// click on an OSC 8 hyperlink on the live screen emits a LinkClickedMsg
+// instead of being forwarded to the child or starting a selection.
+func (m *Model) SetLinkClicks(enabled bool) {
+	m.linkClicks = enabled
+}
+
+// clickLink handles a Ctrl+click at (x, y) on a hyperlink. It returns the
+// command emitting LinkClickedMsg and reports whether the event was consumed,
+// which includes the release of a click on a link.
+func (m *Model) clickLink(msg tea.MouseMsg, x, y int) (tea.Cmd, bool) {
+	switch msg.(type) {
+	case tea.MouseReleaseMsg:
+		if m.linkPressed {
+			m.linkPressed = false
+			return nil, true
+		}
+		return nil, false
+	case tea.MouseClickMsg:
+	default:
+		return nil, false
+	}
+
+	mouse := msg.Mouse()
+	if !m.linkClicks || mouse.Button != tea.MouseLeft || mouse.Mod&tea.ModCtrl == 0 {
+		return nil, false
+	}
+	// Only rows of the live screen carry link data; y is past the history
+	// shown above it when scrolled back.
+	link, ok := m.emulator.LinkAt(x, y-m.scrollOffset)
+	if !ok {
+		return nil, false
+	}
+	m.linkPressed = true
+	id := m.emulator.ID()
+	return func() tea.Msg {
+		return LinkClickedMsg{ID: id, URL: link.URL, Params: link.Params, Text: link.Text}
+	}, true
+}
@@ -0,0 +1,50 @@
+package bubbleterm
+
+import (
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestModelLinkClick(t *testing.T) {
+	model, pw, input := newInputModel(t)
+	if _, err := pw.Write([]byte("see \x1b]8;;file:///tmp/a.go\x07a.go\x1b]8;;\x07")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(model.GetEmulator().Links()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the link")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	click := tea.Mouse{X: 5, Y: 0, Button: tea.MouseLeft, Mod: tea.ModCtrl}
+
+	// Link clicks are off by default
+	if _, cmd := model.Update(tea.MouseClickMsg(click)); cmd != nil {
+		t.Fatal("expected Ctrl+click to be ignored without SetLinkClicks")
+	}
+	model.Update(tea.MouseReleaseMsg(click))
+
+	model.SetLinkClicks(true)
+	_, cmd := model.Update(tea.MouseClickMsg(click))
+	msg, ok := findMsg[LinkClickedMsg](cmd)
+	if !ok {
+		t.Fatal("expected a LinkClickedMsg")
+	}
+	want := LinkClickedMsg{ID: model.GetEmulator().ID(), URL: "file:///tmp/a.go", Text: "a.go"}
+	if msg != want {
+		t.Fatalf("message %+v, want %+v", msg, want)
+	}
+	if _, cmd := model.Update(tea.MouseReleaseMsg(click)); cmd != nil {
+		t.Fatal("expected the release of a link click to be swallowed")
+	}
+
+	// Clicks off a link are handled as usual
+	click.X = 1
+	if _, cmd := model.Update(tea.MouseClickMsg(click)); cmd != nil {
+		t.Fatal("expected Ctrl+click off a link to start a selection")
+	}
+	expectNoInput(t, input)
+}
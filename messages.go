@@ -25,6 +25,13 @@ type startCommandMsg struct {
 	EmulatorID string
 }
 
+// terminalEventMsg carries an exported event message (e.g. TitleChangedMsg)
+// from the poll loop into Update, which re-emits it to the parent
+type terminalEventMsg struct {
+	Msg        tea.Msg
+	EmulatorID string
+}
+
 // Commands (side effects)
 
 // pollTerminal blocks until the emulator signals new damage, then returns the
@@ -32,8 +39,19 @@ type startCommandMsg struct {
 // returned message reschedules the next poll via Update. Do not use it from an
 // external ticker; use pollTerminalOnce for manually driven polling.
 func pollTerminal(emu *emulator.Emulator) tea.Cmd {
+	return pollTerminalEvents(emu, nil)
+}
+
+// pollTerminalEvents is pollTerminal that also wakes up for events queued by
+// the emulator callbacks, returning them as terminalEventMsg. A nil events
+// queue never delivers.
+func pollTerminalEvents(emu *emulator.Emulator, events *eventQueue) tea.Cmd {
 	return func() tea.Msg {
 		done := emu.Done()
+		var ready <-chan struct{}
+		if events != nil {
+			ready = events.ready
+		}
 		// Capture notify before GetScreen so any signal that arrives during
 		// or after GetScreen is not lost: the buffered channel holds it
 		// until the select below reads it.
@@ -41,7 +59,7 @@ func pollTerminal(emu *emulator.Emulator) tea.Cmd {
 
 		// Check for existing damage first (e.g. the initial frame) before
 		// blocking on the channel.
-		if frame := emu.GetScreen(); len(frame.Damage) > 0 {
+		if frame := emu.GetScreen(); frameChanged(frame) {
 			return terminalOutputMsg{Frame: frame, EmulatorID: emu.ID()}
 		}
 
@@ -49,16 +67,27 @@ func pollTerminal(emu *emulator.Emulator) tea.Cmd {
 			select {
 			case <-done:
 				return nil
+			case <-ready:
+				if msg, ok := events.pop(); ok {
+					return terminalEventMsg{Msg: msg, EmulatorID: emu.ID()}
+				}
+				continue
 			case <-notify:
 			}
 			frame := emu.GetScreen()
-			if len(frame.Damage) > 0 {
+			if frameChanged(frame) {
 				return terminalOutputMsg{Frame: frame, EmulatorID: emu.ID()}
 			}
 		}
 	}
 }
 
+// frameChanged reports whether a frame carries anything to redraw: damaged
+// rows or a cursor that moved or changed appearance.
+func frameChanged(frame emulator.EmittedFrame) bool {
+	return len(frame.Damage) > 0 || frame.CursorChanged
+}
+
 // pollTerminalOnce checks the emulator a single time and returns immediately.
 // It returns a terminalOutputMsg only when the screen has changed; otherwise
 // it returns nil so bubbletea performs no View/render cycle. This is the poll
@@ -67,7 +96,7 @@ func pollTerminal(emu *emulator.Emulator) tea.Cmd {
 func pollTerminalOnce(emu *emulator.Emulator) tea.Cmd {
 	return func() tea.Msg {
 		frame := emu.GetScreen()
-		if len(frame.Damage) == 0 {
+		if !frameChanged(frame) {
 			return nil
 		}
 		return terminalOutputMsg{Frame: frame, EmulatorID: emu.ID()}
@@ -85,6 +114,42 @@ func sendInput(emu *emulator.Emulator, input string) tea.Cmd {
 	}
 }
 
+// sendPaste sends pasted text to the terminal
+func sendPaste(emu *emulator.Emulator, text string) tea.Cmd {
+	return func() tea.Msg {
+		err := emu.Paste(text)
+		if err != nil {
+			return terminalErrorMsg{Err: err, EmulatorID: emu.ID()}
+		}
+		return nil
+	}
+}
+
+// sendFocus reports a focus change to the terminal
+func sendFocus(emu *emulator.Emulator, focused bool) tea.Cmd {
+	return func() tea.Msg {
+		err := emu.SendFocus(focused)
+		if err != nil {
+			return terminalErrorMsg{Err: err, EmulatorID: emu.ID()}
+		}
+		return nil
+	}
+}
+
+// mouseButton converts a bubbletea mouse button to the numbering used by
+// Emulator.SendMouse, where left, middle and right are 0, 1 and 2.
+func mouseButton(b tea.MouseButton) int {
+	switch b {
+	case tea.MouseLeft:
+		return 0
+	case tea.MouseMiddle:
+		return 1
+	case tea.MouseRight:
+		return 2
+	}
+	return int(b)
+}
+
 // sendMouseEvent sends a mouse event to the terminal
 func sendMouseEvent(emu *emulator.Emulator, x, y, button int, pressed bool) tea.Cmd {
 	return func() tea.Msg {
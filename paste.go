@@ -0,0 +1,32 @@
+package bubbleterm
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// SanitizePaste is the default paste sanitizer. It removes bracketed paste
+// markers, so pasted text cannot end the paste early and have the rest run as
+// typed input, and control characters other than tab, newline and carriage
+// return, so pasted text cannot inject escape sequences or job control keys.
+func SanitizePaste(text string) string {
+	for _, marker := range []string{ansi.BracketedPasteStart, ansi.BracketedPasteEnd} {
+		text = strings.ReplaceAll(text, marker, "")
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			return r
+		case r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0):
+			return -1
+		}
+		return r
+	}, text)
+}
+
+// SetPasteSanitizer sets the function applied to pasted text before it is sent
+// to the child. The default is SanitizePaste; nil sends pastes verbatim.
+func (m *Model) SetPasteSanitizer(sanitize func(string) string) {
+	m.sanitizePaste = sanitize
+}
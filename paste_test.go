@@ -0,0 +1,117 @@
+package bubbleterm
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func TestSanitizePaste(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "echo hi\nls -l\n", "echo hi\nls -l\n"},
+		{"tabs and crlf", "a\tb\r\n", "a\tb\r\n"},
+		{"end marker", "safe\x1b[201~rm -rf ~\n", "saferm -rf ~\n"},
+		{"start marker", "\x1b[200~x", "x"},
+		{"escape sequence", "\x1b]0;pwned\x07ok", "]0;pwnedok"},
+		{"c0 and del", "a\x03b\x1ac\x7f", "abc"},
+		{"c1", "a\u009b31mb", "a31mb"},
+		{"unicode", "héllo 世界", "héllo 世界"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizePaste(tt.in); got != tt.want {
+				t.Fatalf("SanitizePaste(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// newInputModel returns a pipe-backed model, the writer for the child's output
+// and a channel carrying everything the model sends to the child.
+func newInputModel(t *testing.T) (*Model, *io.PipeWriter, <-chan string) {
+	t.Helper()
+	pr, pw := io.Pipe()
+	ir, iw := io.Pipe()
+
+	model, err := NewWithPipes(20, 3, pr, iw)
+	if err != nil {
+		t.Fatalf("NewWithPipes failed: %v", err)
+	}
+	t.Cleanup(func() { model.Close() })
+
+	input := make(chan string, 16)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := ir.Read(buf)
+			if err != nil {
+				return
+			}
+			input <- string(buf[:n])
+		}
+	}()
+	return model, pw, input
+}
+
+// paste runs the command Update returns for a PasteMsg and returns what the
+// child received.
+func paste(t *testing.T, model *Model, input <-chan string, text string) string {
+	t.Helper()
+	_, cmd := model.Update(tea.PasteMsg{Content: text})
+	if cmd == nil {
+		t.Fatal("expected a command for the paste")
+	}
+	if msg := cmd(); msg != nil {
+		t.Fatalf("expected nil message for a successful paste, got %#v", msg)
+	}
+	select {
+	case got := <-input:
+		return got
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for pasted input")
+	}
+	return ""
+}
+
+func TestPasteWithoutBracketedPaste(t *testing.T) {
+	model, _, input := newInputModel(t)
+
+	if got := paste(t, model, input, "echo a\necho b\n"); got != "echo a\recho b\r" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestPasteBracketed(t *testing.T) {
+	model, pw, input := newInputModel(t)
+
+	writeMode(t, pw, "\x1b[?2004h", model.GetEmulator().IsBracketedPaste)
+
+	got := paste(t, model, input, "a\x1b[201~b\nc")
+	if got != "\x1b[200~ab\rc\x1b[201~" {
+		t.Fatalf("got %q", got)
+	}
+
+	model.SetPasteSanitizer(nil)
+	got = paste(t, model, input, "\x1bx")
+	if got != "\x1b[200~\x1bx\x1b[201~" {
+		t.Fatalf("unsanitized paste got %q", got)
+	}
+}
+
+func TestPasteIgnoredWhenBlurred(t *testing.T) {
+	model, _, _ := newInputModel(t)
+	model.Blur()
+
+	if _, cmd := model.Update(tea.PasteMsg{Content: "ls\n"}); cmd != nil {
+		t.Fatal("expected no command while blurred")
+	}
+	model.Focus()
+	if _, cmd := model.Update(tea.PasteMsg{Content: strings.Repeat("\x03", 3)}); cmd != nil {
+		t.Fatal("expected no command for a paste that sanitizes to nothing")
+	}
+}
@@ -0,0 +1,161 @@
+package bubbleterm
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/taigrr/bubbleterm/emulator"
+)
+
+// wheelScrollLines is the number of history lines one mouse wheel notch
+// scrolls when the child has not enabled mouse tracking.
+const wheelScrollLines = 3
+
+// ScrollOffset returns how many lines the view is scrolled back into the
+// scrollback history. Zero means the live screen is shown, as it always is
+// while the child shows the alternate screen.
+func (m *Model) ScrollOffset() int {
+	return m.scrollOffset
+}
+
+// ScrollUp scrolls the view n lines back into the scrollback history.
+func (m *Model) ScrollUp(n int) {
+	m.setScrollOffset(m.scrollOffset + n)
+}
+
+// ScrollDown scrolls the view n lines towards the live screen.
+func (m *Model) ScrollDown(n int) {
+	m.setScrollOffset(m.scrollOffset - n)
+}
+
+// ScrollToBottom snaps the view back to the live screen.
+func (m *Model) ScrollToBottom() {
+	m.setScrollOffset(0)
+}
+
+// setScrollOffset clamps offset to the available history and re-renders the
+// view when it changes.
+func (m *Model) setScrollOffset(offset int) {
+	if m.altScreen {
+		return // the alternate screen has no history
+	}
+	offset = max(0, min(offset, m.emulator.ScrollbackLen()))
+	if offset == m.scrollOffset {
+		return
+	}
+	m.scrollOffset = offset
+	m.renderView()
+}
+
+// handleScrollKey scrolls the view a page at a time on Shift+PgUp/PgDn. It
+// reports whether the key was consumed.
+func (m *Model) handleScrollKey(msg tea.KeyMsg) bool {
+	if _, ok := msg.(tea.KeyPressMsg); !ok || m.altScreen {
+		return false
+	}
+	k := msg.Key()
+	if k.Mod != tea.ModShift {
+		return false
+	}
+	switch k.Code {
+	case tea.KeyPgUp:
+		m.ScrollUp(max(1, m.height-1))
+		return true
+	case tea.KeyPgDown:
+		m.ScrollDown(max(1, m.height-1))
+		return true
+	}
+	return false
+}
+
+// scrollWheel scrolls the view for a wheel event the child did not ask for.
+func (m *Model) scrollWheel(button tea.MouseButton) {
+	switch button {
+	case tea.MouseWheelUp:
+		m.ScrollUp(wheelScrollLines)
+	case tea.MouseWheelDown:
+		m.ScrollDown(wheelScrollLines)
+	}
+}
+
+// followScreenSwitch turns the scrollback view and selection off while the
+// child shows the alternate screen, as reported by a CRScreenSwitch in the
+// current frame, and restores the scroll position when it returns to the main
+// screen.
+func (m *Model) followScreenSwitch() {
+	if !slices.ContainsFunc(m.frame.Damage, func(d emulator.LineDamage) bool {
+		return d.Reason == emulator.CRScreenSwitch
+	}) {
+		return
+	}
+	alt := m.emulator.IsAltScreen()
+	if alt == m.altScreen {
+		return
+	}
+	m.sel = selection{}
+	if alt {
+		m.mainScrollOffset, m.scrollOffset = m.scrollOffset, 0
+	} else {
+		m.scrollOffset, m.mainScrollOffset = m.mainScrollOffset, 0
+	}
+	m.altScreen = alt
+}
+
+// followScrollback keeps a scrolled-back view and the selection anchored on
+// the same history lines while new output pushes more lines into the
+// scrollback buffer and the oldest ones are dropped from it.
+func (m *Model) followScrollback() {
+	n := m.emulator.ScrollbackLen()
+	dropped := m.emulator.ScrollbackDropped() - m.scrollbackDropped
+	if m.scrollOffset > 0 && n+dropped > m.scrollbackLen {
+		m.scrollOffset += n + dropped - m.scrollbackLen
+	}
+	m.scrollOffset = min(m.scrollOffset, n)
+	m.scrollbackLen = n
+	m.scrollbackDropped += dropped
+	m.shiftSelection(dropped)
+}
+
+// renderView rebuilds cachedView from the current frame, splicing in
+// scrollback rows when the view is scrolled back and drawing the selection
+// and the inline cursor when enabled.
+func (m *Model) renderView() {
+	rows := m.frame.Rows
+	sbLen := m.emulator.ScrollbackLen()
+	top := sbLen - m.scrollOffset
+	if m.scrollOffset > 0 {
+		rows = make([]string, len(m.frame.Rows))
+		for y := range rows {
+			if i := top + y; i < sbLen {
+				rows[y] = m.emulator.ScrollbackRow(i)
+			} else {
+				rows[y] = m.frame.Rows[i-sbLen]
+			}
+		}
+	}
+	if m.sel.active {
+		rows = m.withSelection(rows, top)
+	}
+	if m.inlineCursor && m.showCursor() {
+		rows = withInlineCursor(rows, m.frame.Cursor)
+	}
+	if m.scrollOffset > 0 && len(rows) > 0 {
+		rows[0] = withScrollIndicator(rows[0], m.scrollOffset, m.width)
+	}
+	m.cachedView = strings.Join(rows, "\n")
+}
+
+// withScrollIndicator overlays a reverse-video "scrolled N lines" label on the
+// right edge of row.
+func withScrollIndicator(row string, offset, width int) string {
+	label := "[scrolled " + strconv.Itoa(offset) + " lines]"
+	if offset == 1 {
+		label = "[scrolled 1 line]"
+	}
+	label = ansi.Truncate(label, width, "")
+	keep := width - ansi.StringWidth(label)
+	return ansi.Truncate(row, keep, "") + "\x1b[0m\x1b[7m" + label + "\x1b[0m"
+}
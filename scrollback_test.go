@@ -0,0 +1,233 @@
+package bubbleterm
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// enableMouseTracking turns on normal mouse tracking (DECSET 1000) the way a
+// child program would and waits for the emulator to pick it up.
+func enableMouseTracking(t *testing.T, model *Model, pw *io.PipeWriter) {
+	t.Helper()
+	if _, err := pw.Write([]byte("\x1b[?1000h")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !model.GetEmulator().IsMouseTracking() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for mouse tracking to be enabled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// newScrolledModel returns a 40x4 model whose child has printed n numbered
+// lines, with the resulting frame already applied.
+func newScrolledModel(t *testing.T, n int) (*Model, *io.PipeWriter, *io.PipeReader) {
+	t.Helper()
+	pr, pw := io.Pipe()
+	ir, iw := io.Pipe()
+
+	model, err := NewWithPipes(40, 4, pr, iw)
+	if err != nil {
+		t.Fatalf("NewWithPipes failed: %v", err)
+	}
+	t.Cleanup(func() { model.Close() })
+
+	var out strings.Builder
+	for i := range n {
+		out.WriteString("line" + strconv.Itoa(i) + "\r\n")
+	}
+	if _, err := pw.Write([]byte(out.String())); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+
+	want := n - 3 // the screen keeps three lines plus the cursor row
+	deadline := time.Now().Add(2 * time.Second)
+	for model.GetEmulator().ScrollbackLen() < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d scrollback lines", want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	applyFrame(model)
+	return model, pw, ir
+}
+
+// applyFrame feeds the emulator's current screen through Update.
+func applyFrame(model *Model) {
+	frame := model.GetEmulator().GetScreen()
+	if !frameChanged(frame) {
+		return
+	}
+	model.Update(terminalOutputMsg{Frame: frame, EmulatorID: model.GetEmulator().ID()})
+}
+
+func viewRows(model *Model) []string {
+	return strings.Split(model.View().Content, "\n")
+}
+
+func TestModelMouseWheelScrollsHistoryWithoutMouseTracking(t *testing.T) {
+	model, _, _ := newScrolledModel(t, 10)
+
+	_, cmd := model.Update(tea.MouseWheelMsg{X: 1, Y: 1, Button: tea.MouseWheelUp})
+	if cmd != nil {
+		t.Fatal("expected wheel to be handled locally without mouse tracking")
+	}
+	if got := model.ScrollOffset(); got != wheelScrollLines {
+		t.Fatalf("ScrollOffset() = %d, want %d", got, wheelScrollLines)
+	}
+
+	rows := viewRows(model)
+	if !strings.HasPrefix(rows[0], "line4") {
+		t.Errorf("top row = %q, want history line4", rows[0])
+	}
+	if !strings.Contains(rows[0], "[scrolled 3 lines]") {
+		t.Errorf("top row = %q, want scroll indicator", rows[0])
+	}
+
+	model.Update(tea.MouseWheelMsg{X: 1, Y: 1, Button: tea.MouseWheelDown})
+	if got := model.ScrollOffset(); got != 0 {
+		t.Fatalf("ScrollOffset() = %d after wheel down, want 0", got)
+	}
+	if strings.Contains(model.View().Content, "scrolled") {
+		t.Error("expected indicator to disappear at the live screen")
+	}
+}
+
+func TestModelScrollClampsToHistory(t *testing.T) {
+	model, _, _ := newScrolledModel(t, 6)
+
+	model.ScrollUp(100)
+	if got := model.ScrollOffset(); got != 3 {
+		t.Fatalf("ScrollOffset() = %d, want clamp to 3", got)
+	}
+	if rows := viewRows(model); !strings.HasPrefix(rows[0], "line0") {
+		t.Errorf("top row = %q, want oldest history line", rows[0])
+	}
+
+	model.ScrollDown(100)
+	if got := model.ScrollOffset(); got != 0 {
+		t.Fatalf("ScrollOffset() = %d, want 0", got)
+	}
+}
+
+func TestModelShiftPageKeysScrollHistory(t *testing.T) {
+	model, _, _ := newScrolledModel(t, 20)
+
+	_, cmd := model.Update(tea.KeyPressMsg{Code: tea.KeyPgUp, Mod: tea.ModShift})
+	if cmd != nil {
+		t.Fatal("expected Shift+PgUp to be handled locally")
+	}
+	if got := model.ScrollOffset(); got != 3 {
+		t.Fatalf("ScrollOffset() = %d after Shift+PgUp, want 3", got)
+	}
+
+	model.Update(tea.KeyPressMsg{Code: tea.KeyPgDown, Mod: tea.ModShift})
+	if got := model.ScrollOffset(); got != 0 {
+		t.Fatalf("ScrollOffset() = %d after Shift+PgDn, want 0", got)
+	}
+}
+
+func TestModelSnapsBackOnInput(t *testing.T) {
+	model, _, ir := newScrolledModel(t, 10)
+	go io.Copy(io.Discard, ir)
+
+	model.ScrollUp(2)
+	_, cmd := model.Update(tea.KeyPressMsg{Code: 'a', Text: "a"})
+	if cmd == nil {
+		t.Fatal("expected key to be forwarded to the child")
+	}
+	if got := model.ScrollOffset(); got != 0 {
+		t.Fatalf("ScrollOffset() = %d after typing, want 0", got)
+	}
+}
+
+func TestModelScrolledViewStaysAnchoredOnOutput(t *testing.T) {
+	model, pw, _ := newScrolledModel(t, 10)
+
+	model.ScrollUp(2)
+	before := viewRows(model)[1]
+
+	if _, err := pw.Write([]byte("more\r\n")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for model.GetEmulator().ScrollbackLen() < 8 {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for new scrollback line")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	applyFrame(model)
+
+	if got := model.ScrollOffset(); got != 3 {
+		t.Fatalf("ScrollOffset() = %d after output, want 3", got)
+	}
+	if after := viewRows(model)[1]; after != before {
+		t.Errorf("view moved while scrolled back: %q -> %q", before, after)
+	}
+}
+
+func TestWithScrollIndicator(t *testing.T) {
+	got := withScrollIndicator("abcdefghijklmnopqrstuvwxyz", 1, 26)
+	if !strings.HasPrefix(got, "abcdefgh") || !strings.HasSuffix(got, "[scrolled 1 line]\x1b[0m") {
+		t.Errorf("withScrollIndicator() = %q", got)
+	}
+
+	narrow := withScrollIndicator("abc", 12, 5)
+	if w := len(strings.TrimSuffix(strings.TrimPrefix(narrow, "\x1b[0m\x1b[7m"), "\x1b[0m")); w != 5 {
+		t.Errorf("withScrollIndicator() = %q, want label truncated to width", narrow)
+	}
+}
+
+// switchScreen writes seq to the child, waits until the emulator shows the
+// alternate screen or not as wantAlt says, and applies the frame.
+func switchScreen(t *testing.T, model *Model, pw *io.PipeWriter, seq string, wantAlt bool) {
+	t.Helper()
+	if _, err := pw.Write([]byte(seq)); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for model.GetEmulator().IsAltScreen() != wantAlt {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for IsAltScreen() == %v", wantAlt)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	applyFrame(model)
+}
+
+func TestModelAltScreenDisablesScrollback(t *testing.T) {
+	model, pw, _ := newScrolledModel(t, 10)
+	model.ScrollUp(2)
+
+	switchScreen(t, model, pw, "\x1b[?1049h", true)
+	if model.ScrollOffset() != 0 {
+		t.Fatalf("scroll offset %d on the alternate screen, want 0", model.ScrollOffset())
+	}
+	model.ScrollUp(3)
+	model.Update(tea.KeyPressMsg{Code: tea.KeyPgUp, Mod: tea.ModShift})
+	if model.ScrollOffset() != 0 {
+		t.Fatalf("scrolled to %d on the alternate screen", model.ScrollOffset())
+	}
+	model.Update(tea.MouseClickMsg{X: 1, Y: 1, Button: tea.MouseLeft})
+	model.Update(tea.MouseMotionMsg{X: 5, Y: 1, Button: tea.MouseLeft})
+	model.Update(tea.MouseReleaseMsg{X: 5, Y: 1, Button: tea.MouseLeft})
+	if got := model.Selection(); got != "" {
+		t.Fatalf("selected %q on the alternate screen", got)
+	}
+
+	switchScreen(t, model, pw, "\x1b[?1049l", false)
+	if model.ScrollOffset() != 2 {
+		t.Fatalf("scroll offset %d after leaving the alternate screen, want 2", model.ScrollOffset())
+	}
+	if rows := viewRows(model); !strings.HasPrefix(rows[0], "line5") {
+		t.Fatalf("first row %q after leaving the alternate screen, want line5", rows[0])
+	}
+}
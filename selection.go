@@ -0,0 +1,337 @@
+package bubbleterm
+
+import (
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	uv "github.com/charmbracelet/ultraviolet"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/taigrr/bubbleterm/emulator"
+)
+
+// multiClickInterval is the longest pause between clicks on the same cell
+// that still counts as a double or triple click.
+const multiClickInterval = 500 * time.Millisecond
+
+// wordDelimiters are the non-blank characters that end a word selected by
+// double-click.
+const wordDelimiters = "\"'`()[]{}<>,;|"
+
+// selectionUnit is the granularity a selection grows by while dragging.
+type selectionUnit int
+
+const (
+	selectChar selectionUnit = iota // single click: characters
+	selectWord                      // double click: words
+	selectLine                      // triple click: whole lines
+)
+
+// textPos is a cell in the terminal's history. Lines below ScrollbackLen
+// index the scrollback buffer; the live screen follows, so a selection stays
+// on the same text while the view scrolls.
+type textPos struct {
+	line, col int
+}
+
+// before reports whether p comes before q in reading order.
+func (p textPos) before(q textPos) bool {
+	return p.line < q.line || p.line == q.line && p.col < q.col
+}
+
+// selection is the state of a mouse text selection.
+type selection struct {
+	anchor, head textPos // where the drag started and where it is now
+	unit         selectionUnit
+	rect         bool // Alt was held: select a rectangular block
+	active       bool // a selection is shown
+	dragging     bool // the left button is held
+
+	lastClick    time.Time
+	lastClickPos textPos
+	clicks       int // consecutive clicks on lastClickPos, 1 to 3
+}
+
+// Selection returns the selected text with trailing blanks trimmed from each
+// line and lines joined by "\n", or "" when nothing is selected.
+func (m *Model) Selection() string {
+	if !m.sel.active {
+		return ""
+	}
+	sbLen := m.emulator.ScrollbackLen()
+	screen := m.emulator.GetCells()
+	start, end := m.selectionBounds(sbLen, screen)
+
+	lines := make([]string, 0, end.line-start.line+1)
+	for line := start.line; line <= end.line; line++ {
+		from, to := m.selectedCols(line, start, end)
+		lines = append(lines, cellsText(m.lineCells(line, sbLen, screen), from, to))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ClearSelection removes the current selection, if any.
+func (m *Model) ClearSelection() {
+	if !m.sel.active && !m.sel.dragging {
+		return
+	}
+	m.sel.active = false
+	m.sel.dragging = false
+	m.renderView()
+}
+
+// shiftSelection moves the selection up by n history lines after as many
+// were dropped from the top of the scrollback, and clears it once its text
+// is gone.
+func (m *Model) shiftSelection(n int) {
+	if n == 0 {
+		return
+	}
+	m.sel.anchor.line -= n
+	m.sel.head.line -= n
+	m.sel.lastClickPos.line -= n
+	if m.sel.anchor.line < 0 || m.sel.head.line < 0 {
+		m.sel.active = false
+		m.sel.dragging = false
+	}
+}
+
+// followDamage clears the selection when the current frame redrew any of its
+// rows on the screen, since the text it covered changed. Rows that scrolled
+// keep it: the selection moves with them into the scrollback.
+func (m *Model) followDamage() {
+	if !m.sel.active {
+		return
+	}
+	first, last := m.sel.anchor.line, m.sel.head.line
+	if last < first {
+		first, last = last, first
+	}
+	for _, d := range m.frame.Damage {
+		if line := m.scrollbackLen + d.Row; d.Reason != emulator.CRScroll && line >= first && line <= last {
+			m.sel.active = false
+			m.sel.dragging = false
+			return
+		}
+	}
+}
+
+// selectMouse handles a mouse event at (x, y) as text selection when the
+// child has not enabled mouse tracking, or when Shift is held, except on the
+// alternate screen. It reports whether the event was consumed and must not be
+// forwarded to the child.
+func (m *Model) selectMouse(msg tea.MouseMsg, x, y int) bool {
+	if m.altScreen {
+		return false // full-screen programs get the mouse, if they track it
+	}
+	mouse := msg.Mouse()
+	own := !m.emulator.IsMouseTracking() || mouse.Mod&tea.ModShift != 0
+	pos := m.textPos(x, y)
+
+	switch msg.(type) {
+	case tea.MouseClickMsg:
+		if !own {
+			return false
+		}
+		if mouse.Button != tea.MouseLeft {
+			m.ClearSelection()
+			return false
+		}
+		m.startSelection(pos, mouse.Mod&tea.ModAlt != 0)
+		return true
+
+	case tea.MouseMotionMsg:
+		if !m.sel.dragging {
+			return false
+		}
+		if pos != m.sel.head {
+			m.sel.head = pos
+			m.sel.active = true
+			m.renderView()
+		}
+		return true
+
+	case tea.MouseReleaseMsg:
+		if !m.sel.dragging {
+			return false
+		}
+		m.sel.dragging = false
+		if pos != m.sel.head {
+			m.sel.head = pos
+			m.sel.active = true
+			m.renderView()
+		}
+		return true
+	}
+	return false
+}
+
+// startSelection begins a selection at pos. Repeated clicks on the same cell
+// select by word and then by line; a single click only clears the previous
+// selection until the mouse is dragged.
+func (m *Model) startSelection(pos textPos, rect bool) {
+	now := time.Now()
+	if pos == m.sel.lastClickPos && now.Sub(m.sel.lastClick) <= multiClickInterval {
+		m.sel.clicks = m.sel.clicks%3 + 1
+	} else {
+		m.sel.clicks = 1
+	}
+	m.sel.lastClick = now
+	m.sel.lastClickPos = pos
+
+	m.sel.anchor = pos
+	m.sel.head = pos
+	m.sel.unit = selectionUnit(m.sel.clicks - 1)
+	m.sel.rect = rect
+	m.sel.dragging = true
+	m.sel.active = m.sel.unit != selectChar
+	m.renderView()
+}
+
+// textPos converts a view cell to a position in the terminal's history.
+func (m *Model) textPos(x, y int) textPos {
+	top := m.emulator.ScrollbackLen() - m.scrollOffset
+	y = max(0, min(y, m.height-1))
+	x = max(0, min(x, m.width-1))
+	return textPos{line: top + y, col: x}
+}
+
+// selectionBounds returns the first and last selected cells in reading order,
+// widened to whole words or lines for double and triple click selections.
+func (m *Model) selectionBounds(sbLen int, screen [][]uv.Cell) (start, end textPos) {
+	start, end = m.sel.anchor, m.sel.head
+	if end.before(start) {
+		start, end = end, start
+	}
+	if m.sel.rect {
+		return start, end
+	}
+	switch m.sel.unit {
+	case selectWord:
+		start.col = wordStart(m.lineCells(start.line, sbLen, screen), start.col)
+		end.col = wordEnd(m.lineCells(end.line, sbLen, screen), end.col)
+	case selectLine:
+		start.col = 0
+		end.col = m.width - 1
+	}
+	return start, end
+}
+
+// selectedCols returns the inclusive column range selected on line.
+func (m *Model) selectedCols(line int, start, end textPos) (from, to int) {
+	if m.sel.rect {
+		from, to = m.sel.anchor.col, m.sel.head.col
+		if to < from {
+			from, to = to, from
+		}
+		return from, to
+	}
+	from, to = 0, m.width-1
+	if line == start.line {
+		from = start.col
+	}
+	if line == end.line {
+		to = end.col
+	}
+	return from, to
+}
+
+// withSelection returns a copy of rows, the view starting at history line top,
+// with the selected cells drawn in reverse video.
+func (m *Model) withSelection(rows []string, top int) []string {
+	var screen [][]uv.Cell
+	if m.sel.unit == selectWord && !m.sel.rect {
+		screen = m.emulator.GetCells() // only word bounds depend on the text
+	}
+	start, end := m.selectionBounds(m.emulator.ScrollbackLen(), screen)
+
+	out := make([]string, len(rows))
+	copy(out, rows)
+	for y, row := range rows {
+		line := top + y
+		if line < start.line || line > end.line {
+			continue
+		}
+		from, to := m.selectedCols(line, start, end)
+		if to < from {
+			continue
+		}
+		out[y] = ansi.Truncate(row, from, "") + ansi.ResetStyle + "\x1b[7m" +
+			ansi.Strip(ansi.Cut(row, from, to+1)) + ansi.ResetStyle +
+			ansi.TruncateLeft(row, to+1, "")
+	}
+	return out
+}
+
+// lineCells returns the cells of history line, which may be shorter than the
+// screen width, or nil when it is out of range.
+func (m *Model) lineCells(line, sbLen int, screen [][]uv.Cell) []uv.Cell {
+	if line < sbLen {
+		return m.emulator.ScrollbackLine(line)
+	}
+	if line -= sbLen; line < len(screen) {
+		return screen[line]
+	}
+	return nil
+}
+
+// cellText returns the character at column x, treating the trailing columns
+// of a wide character as that character and missing cells as blanks.
+func cellText(cells []uv.Cell, x int) string {
+	for ; x >= 0 && x < len(cells); x-- {
+		if c := cells[x]; c.Content != "" {
+			return c.Content
+		}
+	}
+	return " "
+}
+
+// isWordChar reports whether s belongs to a word selected by double-click.
+func isWordChar(s string) bool {
+	return strings.TrimSpace(s) != "" && !strings.ContainsAny(s, wordDelimiters)
+}
+
+// wordStart returns the first column of the word at column x, or x itself
+// when it is not in a word.
+func wordStart(cells []uv.Cell, x int) int {
+	if !isWordChar(cellText(cells, x)) {
+		return x
+	}
+	for x > 0 && isWordChar(cellText(cells, x-1)) {
+		x--
+	}
+	return x
+}
+
+// wordEnd returns the last column of the word at column x, or x itself when
+// it is not in a word.
+func wordEnd(cells []uv.Cell, x int) int {
+	if !isWordChar(cellText(cells, x)) {
+		return x
+	}
+	for x+1 < len(cells) && isWordChar(cellText(cells, x+1)) {
+		x++
+	}
+	return x
+}
+
+// cellsText returns the plain text of columns from through to, skipping the
+// trailing columns of wide characters and trimming trailing blanks.
+func cellsText(cells []uv.Cell, from, to int) string {
+	var b strings.Builder
+	for x := from; x <= to; x++ {
+		if x >= len(cells) {
+			break
+		}
+		c := cells[x]
+		if c.Content == "" {
+			if x > 0 && cells[x-1].Width > 1 {
+				continue
+			}
+			b.WriteByte(' ')
+			continue
+		}
+		b.WriteString(c.Content)
+	}
+	return strings.TrimRight(b.String(), " ")
+}
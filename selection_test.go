@@ -0,0 +1,186 @@
+package bubbleterm
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/taigrr/bubbleterm/emulator"
+)
+
+// newSelectionModel returns a 20x3 model whose child has printed two lines of
+// text, with the resulting frame already applied.
+func newSelectionModel(t *testing.T) *Model {
+	t.Helper()
+	model, pw, _ := newInputModel(t)
+	if _, err := pw.Write([]byte("hello world foo\r\nsecond line")); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for cellsText(model.GetEmulator().GetCells()[1], 0, 19) != "second line" {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for output")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	applyFrame(model)
+	return model
+}
+
+// drag clicks at from, drags to to and releases there, expecting the model
+// to handle every event as selection.
+func drag(t *testing.T, model *Model, from, to tea.Mouse) {
+	t.Helper()
+	for _, msg := range []tea.Msg{
+		tea.MouseClickMsg(from),
+		tea.MouseMotionMsg(to),
+		tea.MouseReleaseMsg(to),
+	} {
+		if _, cmd := model.Update(msg); cmd != nil {
+			t.Fatalf("expected %T to be handled as selection", msg)
+		}
+	}
+}
+
+func TestModelSelection(t *testing.T) {
+	model := newSelectionModel(t)
+
+	// A click without a drag selects nothing
+	drag(t, model, tea.Mouse{X: 6, Y: 0, Button: tea.MouseLeft}, tea.Mouse{X: 6, Y: 0, Button: tea.MouseLeft})
+	if got := model.Selection(); got != "" {
+		t.Fatalf("expected no selection after a click, got %q", got)
+	}
+
+	// Dragging backwards selects the same text as dragging forwards
+	drag(t, model, tea.Mouse{X: 2, Y: 1, Button: tea.MouseLeft}, tea.Mouse{X: 6, Y: 0, Button: tea.MouseLeft})
+	if got, want := model.Selection(), "world foo\nsec"; got != want {
+		t.Fatalf("character selection = %q, want %q", got, want)
+	}
+	if row := viewRows(model)[0]; !strings.Contains(row, "\x1b[7mworld foo") {
+		t.Fatalf("expected selection in reverse video, got %q", row)
+	}
+
+	// Alt selects a rectangle
+	drag(t, model, tea.Mouse{X: 0, Y: 0, Button: tea.MouseLeft, Mod: tea.ModAlt}, tea.Mouse{X: 4, Y: 1, Button: tea.MouseLeft})
+	if got, want := model.Selection(), "hello\nsecon"; got != want {
+		t.Fatalf("rectangular selection = %q, want %q", got, want)
+	}
+
+	model.ClearSelection()
+	if got := model.Selection(); got != "" {
+		t.Fatalf("expected ClearSelection to clear the selection, got %q", got)
+	}
+	if strings.Contains(model.View().Content, "\x1b[7m") {
+		t.Fatal("expected no reverse video after ClearSelection")
+	}
+}
+
+func TestModelSelectionMultiClick(t *testing.T) {
+	model := newSelectionModel(t)
+	click := tea.Mouse{X: 8, Y: 0, Button: tea.MouseLeft}
+
+	for _, want := range []string{"", "world", "hello world foo", ""} {
+		drag(t, model, click, click)
+		if got := model.Selection(); got != want {
+			t.Fatalf("selection = %q, want %q", got, want)
+		}
+	}
+
+	// Dragging a double click extends the selection by whole words
+	time.Sleep(multiClickInterval + 10*time.Millisecond)
+	drag(t, model, click, click)
+	drag(t, model, click, tea.Mouse{X: 2, Y: 1, Button: tea.MouseLeft})
+	if got, want := model.Selection(), "world foo\nsecond"; got != want {
+		t.Fatalf("word selection = %q, want %q", got, want)
+	}
+}
+
+func TestModelSelectionDefersToMouseTracking(t *testing.T) {
+	model, pw, input := newInputModel(t)
+	enableMouseTracking(t, model, pw)
+
+	_, cmd := model.Update(tea.MouseClickMsg{X: 1, Y: 1, Button: tea.MouseLeft})
+	if cmd == nil {
+		t.Fatal("expected click to be forwarded to a child tracking the mouse")
+	}
+	cmd()
+	expectInput(t, input, "\x1b[M \"\"")
+
+	// Shift overrides the child's mouse tracking
+	drag(t, model, tea.Mouse{X: 0, Y: 0, Button: tea.MouseLeft, Mod: tea.ModShift}, tea.Mouse{X: 3, Y: 0, Button: tea.MouseLeft})
+	expectNoInput(t, input)
+}
+
+func TestModelSelectionInScrollback(t *testing.T) {
+	model, _, _ := newScrolledModel(t, 10)
+	model.ScrollUp(2)
+
+	drag(t, model, tea.Mouse{X: 0, Y: 0, Button: tea.MouseLeft}, tea.Mouse{X: 4, Y: 1, Button: tea.MouseLeft})
+	want := "line5\nline6"
+	if got := model.Selection(); got != want {
+		t.Fatalf("selection = %q, want %q", got, want)
+	}
+
+	// The selection stays on the same text when the view scrolls
+	model.ScrollToBottom()
+	if got := model.Selection(); got != want {
+		t.Fatalf("selection after scrolling = %q, want %q", got, want)
+	}
+}
+
+// writeLines has the child write s and waits until the emulator has
+// processed it, as reported by done, then applies the resulting frame.
+func writeLines(t *testing.T, model *Model, pw io.Writer, s string, done func(*emulator.Emulator) bool) {
+	t.Helper()
+	if _, err := pw.Write([]byte(s)); err != nil {
+		t.Fatalf("pipe write failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for !done(model.GetEmulator()) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for output")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	applyFrame(model)
+}
+
+func TestModelSelectionFollowsDroppedLines(t *testing.T) {
+	model, pw, _ := newScrolledModel(t, 10)
+	model.ScrollUp(2)
+	drag(t, model, tea.Mouse{X: 0, Y: 0, Button: tea.MouseLeft}, tea.Mouse{X: 4, Y: 1, Button: tea.MouseLeft})
+
+	// Shrinking the scrollback and printing a line drops line0 to line3
+	if err := model.GetEmulator().SetScrollbackSize(4); err != nil {
+		t.Fatalf("SetScrollbackSize failed: %v", err)
+	}
+	writeLines(t, model, pw, "line10\r\n", func(e *emulator.Emulator) bool { return e.ScrollbackDropped() == 4 })
+	if got, want := model.Selection(), "line5\nline6"; got != want {
+		t.Fatalf("selection = %q, want %q", got, want)
+	}
+	if row := viewRows(model)[0]; !strings.Contains(row, "line5") {
+		t.Fatalf("expected the view to stay on line5, got %q", row)
+	}
+}
+
+func TestModelSelectionClearedByOutput(t *testing.T) {
+	model, pw, _ := newScrolledModel(t, 10)
+	drag(t, model, tea.Mouse{X: 0, Y: 1, Button: tea.MouseLeft}, tea.Mouse{X: 4, Y: 1, Button: tea.MouseLeft})
+
+	// Output on another row keeps the selection
+	rowText := func(y int, want string) func(*emulator.Emulator) bool {
+		return func(e *emulator.Emulator) bool { return cellsText(e.GetCells()[y], 0, 39) == want }
+	}
+	writeLines(t, model, pw, "\x1b[4;1Hother", rowText(3, "other"))
+	if got, want := model.Selection(), "line8"; got != want {
+		t.Fatalf("selection = %q, want %q", got, want)
+	}
+
+	// Overwriting the selected row clears it
+	writeLines(t, model, pw, "\x1b[2;1Hchanged", rowText(1, "changed"))
+	if got := model.Selection(); got != "" {
+		t.Fatalf("expected output to clear the selection, got %q", got)
+	}
+}